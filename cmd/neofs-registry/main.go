@@ -0,0 +1,192 @@
+// neofs-registry is a tool to work with object registry files outside of k6,
+// it allows to export registry contents, to import preset results into
+// a registry, to purge objects and to compact registry file.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/nspcc-dev/xk6-neofs/internal/registry"
+)
+
+const usage = `Usage: neofs-registry <command> [flags]
+
+Commands:
+  export  write registry objects to JSON Lines or CSV
+  import  add objects from preset JSON or JSON Lines to registry
+  purge   remove objects matching filter from registry
+  compact rewrite registry file to reclaim free space
+
+Run 'neofs-registry <command> -h' for command flags.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "export":
+		err = runExport(args)
+	case "import":
+		err = runImport(args)
+	case "purge":
+		err = runPurge(args)
+	case "compact":
+		err = runCompact(args)
+	case "-h", "--help", "help":
+		fmt.Fprint(os.Stdout, usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command: '%s'\n\n%s", cmd, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	dbPath := fs.String("registry", "registry.bolt", "path to registry file")
+	format := fs.String("format", registry.FormatJSONL, "output format: jsonl or csv")
+	out := fs.String("out", "", "output file, standard output if empty")
+	filter := filterFlags(fs, "export")
+	_ = fs.Parse(args)
+
+	reg, err := registry.OpenObjRegistry(context.Background(), *dbPath)
+	if err != nil {
+		return fmt.Errorf("open registry: %w", err)
+	}
+	defer reg.Close()
+
+	var (
+		w = io.Writer(os.Stdout)
+		f *os.File
+	)
+	if *out != "" {
+		if f, err = os.Create(*out); err != nil {
+			return err
+		}
+		w = f
+	}
+
+	n, err := reg.Export(w, *format, filter)
+	if f != nil {
+		err = errors.Join(err, f.Close())
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "exported objects:", n)
+	return nil
+}
+
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	dbPath := fs.String("registry", "registry.bolt", "path to registry file")
+	format := fs.String("format", registry.FormatPreset, "input format: preset or jsonl")
+	in := fs.String("in", "", "input file, standard input if empty")
+	_ = fs.Parse(args)
+
+	var r io.Reader = os.Stdin
+	if *in != "" {
+		f, err := os.Open(*in)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	reg, err := registry.OpenObjRegistry(context.Background(), *dbPath)
+	if err != nil {
+		return fmt.Errorf("open registry: %w", err)
+	}
+
+	n, err := reg.Import(r, *format)
+	err = errors.Join(err, reg.Close())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "imported objects:", n)
+	return nil
+}
+
+func runPurge(args []string) error {
+	fs := flag.NewFlagSet("purge", flag.ExitOnError)
+	dbPath := fs.String("registry", "registry.bolt", "path to registry file")
+	filter := filterFlags(fs, "purge")
+	_ = fs.Parse(args)
+
+	reg, err := registry.OpenObjRegistry(context.Background(), *dbPath)
+	if err != nil {
+		return fmt.Errorf("open registry: %w", err)
+	}
+
+	n, err := reg.Purge(filter)
+	err = errors.Join(err, reg.Close())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "purged objects:", n)
+	return nil
+}
+
+func runCompact(args []string) error {
+	fs := flag.NewFlagSet("compact", flag.ExitOnError)
+	dbPath := fs.String("registry", "registry.bolt", "path to registry file")
+	out := fs.String("out", "", "path to compacted file, registry file is replaced if empty")
+	_ = fs.Parse(args)
+
+	dstPath := *out
+	if dstPath == "" {
+		dstPath = *dbPath + ".compact"
+	}
+
+	reg, err := registry.OpenObjRegistry(context.Background(), *dbPath)
+	if err != nil {
+		return fmt.Errorf("open registry: %w", err)
+	}
+	err = reg.Compact(dstPath)
+	err = errors.Join(err, reg.Close())
+	if err != nil {
+		return err
+	}
+
+	before, after := fileSize(*dbPath), fileSize(dstPath)
+	if *out == "" {
+		// Registry is closed, so it's safe to replace the file
+		if err := os.Rename(dstPath, *dbPath); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(os.Stderr, "compacted registry: %d -> %d bytes\n", before, after)
+	return nil
+}
+
+func fileSize(path string) int64 {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return fi.Size()
+}
+
+// filterFlags defines flags of object filter for the command.
+func filterFlags(fs *flag.FlagSet, cmd string) *registry.ObjFilter {
+	var filter registry.ObjFilter
+	fs.StringVar(&filter.Status, "status", "", cmd+" only objects with the status")
+	fs.IntVar(&filter.Age, "age", 0, cmd+" only objects older than the age in seconds")
+	fs.StringVar(&filter.Container, "container", "", cmd+" only objects from the container")
+	fs.StringVar(&filter.Bucket, "bucket", "", cmd+" only objects from the bucket")
+	return &filter
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/xk6-neofs/internal/registry"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportPurgeAge(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "registry.bolt")
+
+	reg, err := registry.OpenObjRegistry(context.Background(), dbPath)
+	require.NoError(t, err)
+	require.NoError(t, reg.AddObject("c", "young", "", "", "h", 0))
+	require.NoError(t, reg.Close())
+
+	// Imported object is older than the existing one, but gets greater ID.
+	in := filepath.Join(dir, "objects.jsonl")
+	createdAt := time.Now().UTC().Add(-48 * time.Hour).Format(time.RFC3339Nano)
+	require.NoError(t, os.WriteFile(in, []byte(`{"c_id":"c","o_id":"old","created_at":"`+createdAt+`"}`+"\n"), 0o600))
+
+	require.NoError(t, runImport([]string{"-registry", dbPath, "-format", registry.FormatJSONL, "-in", in}))
+	require.NoError(t, runPurge([]string{"-registry", dbPath, "-age", "3600"}))
+
+	reg, err = registry.OpenObjRegistry(context.Background(), dbPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = reg.Close() })
+
+	old, err := reg.GetObject(2)
+	require.NoError(t, err)
+	require.Nil(t, old)
+	young, err := reg.GetObject(1)
+	require.NoError(t, err)
+	require.Equal(t, "young", young.OID)
+}
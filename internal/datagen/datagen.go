@@ -1,16 +1,28 @@
 package datagen
 
 import (
+	"fmt"
+	"strconv"
+
 	"go.k6.io/k6/js/modules"
 )
 
 // RootModule is the global module object type. It is instantiated once per test
 // run and will be used to create k6/x/neofs/registry module instances for each VU.
-type RootModule struct{}
+type RootModule struct {
+	// pools stores payload pools shared by all VUs.
+	pools pools
+	// files stores file sources shared by all VUs.
+	files fileSources
+	// keyCounters stores counters of sequential key generators shared by
+	// all VUs.
+	keyCounters keyCounters
+}
 
 // Datagen represents an instance of the module for every VU.
 type Datagen struct {
-	vu modules.VU
+	vu   modules.VU
+	root *RootModule
 }
 
 // Ensure the interfaces are implemented correctly.
@@ -26,7 +38,7 @@ func init() {
 // NewModuleInstance implements the modules.Module interface and returns
 // a new instance for each VU.
 func (r *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
-	mi := &Datagen{vu: vu}
+	mi := &Datagen{vu: vu, root: r}
 	return mi
 }
 
@@ -36,7 +48,101 @@ func (d *Datagen) Exports() modules.Exports {
 	return modules.Exports{Default: d}
 }
 
-func (d *Datagen) Generator(size int) *Generator {
-	g := NewGenerator(d.vu, size)
+// Generator creates payload generator of the specified size. The optional
+// params is a dictionary with `seed` key, which makes generated payloads
+// reproducible from seeds returned along with them, `distribution` key
+// with distribution parameters, which makes payloads of different sizes not
+// bigger than the generator size, and `mode` key with payload mode parameters.
+func (d *Datagen) Generator(size int, params map[string]string) *Generator {
+	var g Generator
+	if seedStr, ok := params["seed"]; ok {
+		seed, err := strconv.ParseUint(seedStr, 10, 64)
+		if err != nil {
+			panic(fmt.Sprintf("invalid value for 'seed': '%s'", seedStr))
+		}
+		g = NewSeededGenerator(d.vu, size, seed)
+	} else {
+		g = NewGenerator(d.vu, size)
+	}
+
+	dist, err := parseDistribution(params, size)
+	if err != nil {
+		panic(err)
+	}
+	g.dist = dist
+
+	if g.mode, err = parsePayloadMode(params); err != nil {
+		panic(err)
+	}
+
 	return &g
 }
+
+// PayloadHash returns hex-encoded SHA-256 hash of the payload of the specified
+// size generated from the seed returned by seeded generator. The params are
+// payload mode parameters the generator was created with. Payload isn't kept
+// in memory, so it can be used to verify objects of any size.
+func (d *Datagen) PayloadHash(size int64, seed string, params map[string]string) string {
+	s, err := strconv.ParseUint(seed, 10, 64)
+	if err != nil {
+		panic(fmt.Sprintf("invalid seed: '%s'", seed))
+	}
+	mode, err := parsePayloadMode(params)
+	if err != nil {
+		panic(err)
+	}
+	return payloadHash(mode, size, s)
+}
+
+// Pool returns payload pool of the specified name shared by all VUs, it's
+// created on the first call with the payload size and params. The optional
+// params is a dictionary with `buffers` key (number of buffers in the pool,
+// 1 by default), `seed` key and `mode` key with payload mode parameters.
+// Panics if the pool already exists with a different size.
+func (d *Datagen) Pool(name string, size int, params map[string]string) *Pool {
+	p, err := d.root.pools.get(name, size, params)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// KeyGenerator creates generator of object keys. The params is a dictionary
+// with `prefix`, `depth` (number of directories, 0 by default), `fanout`
+// (number of subdirectories in every directory, 16 by default), `naming`
+// (`random` or `sequential`), `name` and `seed` keys. Sequential generators
+// with the same `name` (`prefix` by default) share the counter in all VUs.
+func (d *Datagen) KeyGenerator(params map[string]string) *KeyGenerator {
+	g, err := newKeyGenerator(d.vu, params, &d.root.keyCounters)
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
+
+// AttributeGenerator creates generator of attribute maps. The params is a
+// dictionary with `keys` (comma-separated attribute keys), `cardinality`
+// (number of distinct values of every attribute, 100 by default),
+// `distribution` (`uniform` or `zipf` with `zipf_s` and `zipf_v`
+// parameters) and `seed` keys.
+func (d *Datagen) AttributeGenerator(params map[string]string) *AttributeGenerator {
+	g, err := newAttributeGenerator(d.vu, params)
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
+
+// Files creates source of payloads read from the file or files in the
+// directory and its subdirectories. The optional params is a dictionary with
+// `order` key: `sequential` (default), `random` or `weighted` (random with
+// probability proportional to file size). File list and hashes are calculated
+// once per path for all VUs and orders. Panics if there are no files or they
+// can't be read.
+func (d *Datagen) Files(path string, params map[string]string) *Files {
+	f, err := newFiles(&d.root.files, path, params)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
@@ -0,0 +1,268 @@
+package datagen
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type (
+	// sizeDistribution chooses payload size for every generated payload.
+	sizeDistribution interface {
+		next(r *rand.Rand) int
+	}
+
+	// sizeSampler samples raw payload size which may be out of the allowed
+	// range or even infinite.
+	sizeSampler interface {
+		sample(r *rand.Rand) float64
+	}
+
+	// boundedDistribution limits samples to [min, max] range. Samples are
+	// clamped before conversion to int, so that huge values don't overflow.
+	boundedDistribution struct {
+		sampler  sizeSampler
+		min, max int
+	}
+
+	uniformDistribution struct {
+		min, max int
+	}
+
+	normalDistribution struct {
+		mean, stddev float64
+	}
+
+	logNormalDistribution struct {
+		mu, sigma float64
+	}
+
+	paretoDistribution struct {
+		scale, alpha float64
+	}
+
+	// histogramDistribution chooses bucket with probability proportional to
+	// its weight and then uniformly chooses size within the bucket.
+	histogramDistribution struct {
+		buckets []sizeBucket
+		total   float64
+	}
+
+	sizeBucket struct {
+		min, max int
+		weight   float64
+	}
+)
+
+func (d boundedDistribution) next(r *rand.Rand) int {
+	x := d.sampler.sample(r)
+	if math.IsNaN(x) {
+		return d.min
+	}
+	return int(math.Round(math.Min(math.Max(x, float64(d.min)), float64(d.max))))
+}
+
+func (d uniformDistribution) next(r *rand.Rand) int {
+	return d.min + r.IntN(d.max-d.min+1)
+}
+
+func (d uniformDistribution) sample(r *rand.Rand) float64 {
+	return float64(d.next(r))
+}
+
+func (d normalDistribution) sample(r *rand.Rand) float64 {
+	return d.mean + d.stddev*r.NormFloat64()
+}
+
+func (d logNormalDistribution) sample(r *rand.Rand) float64 {
+	return math.Exp(d.mu + d.sigma*r.NormFloat64())
+}
+
+func (d paretoDistribution) sample(r *rand.Rand) float64 {
+	// 1-U is in (0, 1], so the result is positive, but it can be +Inf for
+	// tiny alpha
+	return d.scale / math.Pow(1-r.Float64(), 1/d.alpha)
+}
+
+func (d histogramDistribution) sample(r *rand.Rand) float64 {
+	w := r.Float64() * d.total
+	for _, b := range d.buckets {
+		if w < b.weight {
+			return uniformDistribution{min: b.min, max: b.max}.sample(r)
+		}
+		w -= b.weight
+	}
+	last := d.buckets[len(d.buckets)-1]
+	return uniformDistribution{min: last.min, max: last.max}.sample(r)
+}
+
+// parseDistribution creates size distribution from generator parameters, the
+// maxSize is a generator size. Every distribution is limited by [min, maxSize]
+// range. Returns nil if no distribution is specified.
+func parseDistribution(params map[string]string, maxSize int) (sizeDistribution, error) {
+	name, ok := params["distribution"]
+	if !ok {
+		return nil, nil
+	}
+
+	minSize, err := parseIntParam(params, "min", 0)
+	if err != nil {
+		return nil, err
+	}
+	if minSize < 0 || minSize > maxSize {
+		return nil, fmt.Errorf("'min' should be in [0, %d] range", maxSize)
+	}
+
+	sampler, err := parseSampler(name, params, minSize, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	return boundedDistribution{sampler: sampler, min: minSize, max: maxSize}, nil
+}
+
+func parseSampler(name string, params map[string]string, minSize, maxSize int) (sizeSampler, error) {
+	switch name {
+	case "uniform":
+		return uniformDistribution{min: minSize, max: maxSize}, nil
+	case "normal":
+		mean, err := parseFloatParam(params, "mean", float64(minSize+maxSize)/2)
+		if err != nil {
+			return nil, err
+		}
+		stddev, err := parseFloatParam(params, "stddev", float64(maxSize-minSize)/6)
+		if err != nil {
+			return nil, err
+		}
+		return normalDistribution{mean: mean, stddev: stddev}, nil
+	case "lognormal":
+		median, err := parseFloatParam(params, "median", 0)
+		if err != nil {
+			return nil, err
+		}
+		if median <= 0 {
+			return nil, errors.New("'median' should be positive for lognormal distribution")
+		}
+		sigma, err := parseFloatParam(params, "sigma", 1)
+		if err != nil {
+			return nil, err
+		}
+		return logNormalDistribution{mu: math.Log(median), sigma: sigma}, nil
+	case "pareto":
+		if minSize <= 0 {
+			return nil, errors.New("'min' should be positive for pareto distribution")
+		}
+		alpha, err := parseFloatParam(params, "alpha", 1.16) // 80-20 rule
+		if err != nil {
+			return nil, err
+		}
+		if alpha <= 0 {
+			return nil, errors.New("'alpha' should be positive")
+		}
+		return paretoDistribution{scale: float64(minSize), alpha: alpha}, nil
+	case "histogram":
+		return readHistogram(params["histogram"], maxSize)
+	default:
+		return nil, fmt.Errorf("unknown distribution: '%s'", name)
+	}
+}
+
+// readHistogram reads histogram file, every line of which contains minimum
+// and maximum sizes of the bucket and its weight separated by spaces or
+// commas. Empty lines and lines starting with '#' are ignored. Buckets
+// larger than maxSize are rejected.
+func readHistogram(path string, maxSize int) (histogramDistribution, error) {
+	var d histogramDistribution
+
+	f, err := os.Open(path)
+	if err != nil {
+		return d, fmt.Errorf("open histogram: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+		if len(fields) != 3 {
+			return d, fmt.Errorf("histogram line %d: expected 'min max weight'", line)
+		}
+
+		var b sizeBucket
+		b.min, err = strconv.Atoi(fields[0])
+		if err == nil {
+			b.max, err = strconv.Atoi(fields[1])
+		}
+		if err == nil {
+			b.weight, err = strconv.ParseFloat(fields[2], 64)
+		}
+		if err != nil {
+			return d, fmt.Errorf("histogram line %d: %w", line, err)
+		}
+		if b.min < 0 || b.max < b.min || b.weight < 0 {
+			return d, fmt.Errorf("histogram line %d: invalid bucket", line)
+		}
+		if b.max > maxSize {
+			return d, fmt.Errorf("histogram line %d: bucket exceeds generator size %d", line, maxSize)
+		}
+
+		d.buckets = append(d.buckets, b)
+		d.total += b.weight
+	}
+	if err = scanner.Err(); err != nil {
+		return d, fmt.Errorf("read histogram: %w", err)
+	}
+	if d.total == 0 {
+		return d, errors.New("histogram has no buckets with positive weight")
+	}
+	return d, nil
+}
+
+// sizeClass returns size rounded up to the power of two in human-readable
+// form, it is suitable for metric tags because of the low cardinality.
+func sizeClass(size int) string {
+	if size <= 0 {
+		return "0B"
+	}
+	class := uint64(1) << bits.Len64(uint64(size-1))
+	for _, unit := range []string{"B", "KiB", "MiB", "GiB"} {
+		if class < 1024 {
+			return strconv.FormatUint(class, 10) + unit
+		}
+		class /= 1024
+	}
+	return strconv.FormatUint(class, 10) + "TiB"
+}
+
+func parseIntParam(params map[string]string, name string, def int) (int, error) {
+	s, ok := params[name]
+	if !ok {
+		return def, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for '%s': '%s'", name, s)
+	}
+	return v, nil
+}
+
+func parseFloatParam(params map[string]string, name string, def float64) (float64, error) {
+	s, ok := params[name]
+	if !ok {
+		return def, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for '%s': '%s'", name, s)
+	}
+	return v, nil
+}
@@ -0,0 +1,113 @@
+package datagen
+
+import (
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/sobek"
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/js/modulestest"
+)
+
+func TestDistribution(t *testing.T) {
+	const maxSize = 1000
+
+	histogram := filepath.Join(t.TempDir(), "histogram.txt")
+	require.NoError(t, os.WriteFile(histogram, []byte("# min max weight\n10 20 1\n\n500,500,3\n"), 0o600))
+
+	for name, params := range map[string]map[string]string{
+		"uniform":   {"distribution": "uniform", "min": "100"},
+		"normal":    {"distribution": "normal", "mean": "500", "stddev": "300"},
+		"lognormal": {"distribution": "lognormal", "median": "200", "sigma": "2"},
+		"pareto":    {"distribution": "pareto", "min": "100", "alpha": "1"},
+		"histogram": {"distribution": "histogram", "histogram": histogram},
+	} {
+		t.Run(name, func(t *testing.T) {
+			g := NewGenerator(&modulestest.VU{RuntimeField: sobek.New()}, maxSize)
+			dist, err := parseDistribution(params, maxSize)
+			require.NoError(t, err)
+			g.dist = dist
+
+			sizes := make(map[int]struct{})
+			for range 100 {
+				slice := g.nextSlice()
+				require.LessOrEqual(t, len(slice), maxSize)
+				sizes[len(slice)] = struct{}{}
+			}
+			require.Greater(t, len(sizes), 1, "payloads should have different sizes")
+		})
+	}
+
+	t.Run("histogram buckets", func(t *testing.T) {
+		dist, err := readHistogram(histogram, maxSize)
+		require.NoError(t, err)
+
+		r := rand.New(rand.NewPCG(1, 2))
+		for range 100 {
+			size := int(dist.sample(r))
+			require.True(t, (size >= 10 && size <= 20) || size == 500, size)
+		}
+	})
+
+	t.Run("bounds", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			params map[string]string
+			min    int
+		}{
+			"normal":    {map[string]string{"distribution": "normal", "min": "400", "mean": "0", "stddev": "1000"}, 400},
+			"lognormal": {map[string]string{"distribution": "lognormal", "min": "100", "median": "200", "sigma": "1000"}, 100},
+			"pareto":    {map[string]string{"distribution": "pareto", "min": "100", "alpha": "0.001"}, 100},
+		} {
+			t.Run(name, func(t *testing.T) {
+				dist, err := parseDistribution(tc.params, maxSize)
+				require.NoError(t, err)
+
+				r := rand.New(rand.NewPCG(1, 2))
+				for range 1000 {
+					size := dist.next(r)
+					require.GreaterOrEqual(t, size, tc.min)
+					require.LessOrEqual(t, size, maxSize)
+				}
+			})
+		}
+
+		t.Run("infinity", func(t *testing.T) {
+			r := rand.New(rand.NewPCG(1, 2))
+			dist := boundedDistribution{sampler: paretoDistribution{scale: 1, alpha: 1e-300}, min: 1, max: maxSize}
+			require.Equal(t, maxSize, dist.next(r))
+		})
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		large := filepath.Join(t.TempDir(), "large.txt")
+		require.NoError(t, os.WriteFile(large, []byte("10 20 1\n500 1001 1\n"), 0o600))
+
+		for _, params := range []map[string]string{
+			{"distribution": "unknown"},
+			{"distribution": "uniform", "min": "1001"},
+			{"distribution": "lognormal"},
+			{"distribution": "pareto"},
+			{"distribution": "histogram", "histogram": filepath.Join(t.TempDir(), "missing")},
+			{"distribution": "histogram", "histogram": large},
+		} {
+			_, err := parseDistribution(params, maxSize)
+			require.Error(t, err, params)
+		}
+	})
+}
+
+func TestSizeClass(t *testing.T) {
+	for size, class := range map[int]string{
+		0:           "0B",
+		1:           "1B",
+		1000:        "1KiB",
+		1024:        "1KiB",
+		1025:        "2KiB",
+		3 << 20:     "4MiB",
+		64<<30 - 42: "64GiB",
+	} {
+		require.Equal(t, class, sizeClass(size), size)
+	}
+}
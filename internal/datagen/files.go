@@ -0,0 +1,230 @@
+package datagen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// File orders of the file source.
+const (
+	fileOrderSequential = "sequential"
+	fileOrderRandom     = "random"
+	fileOrderWeighted   = "weighted"
+)
+
+type (
+	// Files returns payloads read from local files. File list and hashes are
+	// shared by all VUs, so files are hashed only once.
+	Files struct {
+		src   *fileSource
+		order string
+	}
+
+	// FilePayloadResponse is a payload read from the file. Payload is kept
+	// out of JS heap, it can be passed to put operations of the clients. Name
+	// is a path relative to the source directory with '/' separators, Hash is
+	// hex-encoded SHA-256 calculated when the source was opened.
+	FilePayloadResponse struct {
+		Success bool
+		Payload *Payload
+		Name    string
+		Hash    string
+		Size    int
+		Error   string
+	}
+
+	// fileSource is a list of files shared by all VUs with any order. The
+	// counter is used by sequential order.
+	fileSource struct {
+		files   []fileEntry
+		total   int64
+		counter atomic.Uint64
+	}
+
+	fileEntry struct {
+		path, name string
+		size       int64
+		modTime    time.Time
+		// offset is a sum of sizes of previous files used for weighted choice.
+		offset int64
+		hash   string
+	}
+
+	// fileSources stores file sources by path.
+	fileSources struct {
+		mu sync.Mutex
+		m  map[string]*fileSource
+	}
+)
+
+func newFiles(sources *fileSources, path string, params map[string]string) (*Files, error) {
+	order := params["order"]
+	switch order {
+	case "":
+		order = fileOrderSequential
+	case fileOrderSequential, fileOrderRandom, fileOrderWeighted:
+	default:
+		return nil, fmt.Errorf("unknown order: '%s'", order)
+	}
+
+	src, err := sources.get(path)
+	if err != nil {
+		return nil, err
+	}
+	if order == fileOrderWeighted && src.total == 0 {
+		return nil, errors.New("all files are empty, can't choose weighted by size")
+	}
+	return &Files{src: src, order: order}, nil
+}
+
+func (s *fileSources) get(path string) (*fileSource, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if src, ok := s.m[path]; ok {
+		return src, nil
+	}
+	src, err := openFileSource(path)
+	if err != nil {
+		return nil, err
+	}
+	if s.m == nil {
+		s.m = make(map[string]*fileSource)
+	}
+	s.m[path] = src
+	return src, nil
+}
+
+// openFileSource lists regular files in the directory and its subdirectories
+// in lexical order (or takes the single file if path is a file) and calculates
+// their hashes.
+func openFileSource(path string) (*fileSource, error) {
+	src := new(fileSource)
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(path, p)
+		if err != nil || name == "." {
+			name = d.Name()
+		}
+		src.files = append(src.files, fileEntry{
+			path:    p,
+			name:    filepath.ToSlash(name),
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list files: %w", err)
+	}
+	if len(src.files) == 0 {
+		return nil, fmt.Errorf("no files in '%s'", path)
+	}
+
+	for i := range src.files {
+		f := &src.files[i]
+		if f.hash, err = fileHash(f.path); err != nil {
+			return nil, err
+		}
+		f.offset = src.total
+		src.total += f.size
+	}
+	return src, nil
+}
+
+func fileHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("hash '%s': %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// next chooses the next file in the specified order, it is safe for
+// concurrent use.
+func (s *fileSource) next(order string) *fileEntry {
+	switch order {
+	case fileOrderRandom:
+		return &s.files[rand.IntN(len(s.files))]
+	case fileOrderWeighted:
+		w := rand.Int64N(s.total)
+		// the last file with offset not greater than w
+		i := sort.Search(len(s.files), func(i int) bool { return s.files[i].offset > w }) - 1
+		return &s.files[i]
+	default:
+		n := s.counter.Add(1) - 1
+		return &s.files[n%uint64(len(s.files))]
+	}
+}
+
+// Next returns payload of the next file. Sequential order is shared by all
+// VUs, so every file is used once before the list is repeated. The whole file
+// is read into memory, so files should be small enough to be kept in memory
+// by all VUs at once. Files changed since the source was opened are not
+// read, since their hashes are outdated.
+func (f *Files) Next() FilePayloadResponse {
+	e := f.src.next(f.order)
+	data, err := e.read()
+	if err != nil {
+		return FilePayloadResponse{Success: false, Name: e.name, Error: err.Error()}
+	}
+	return FilePayloadResponse{
+		Success: true,
+		Payload: &Payload{data: data},
+		Name:    e.name,
+		Hash:    e.hash,
+		Size:    len(data),
+	}
+}
+
+// read returns contents of the file checking that it hasn't been changed
+// since it was listed.
+func (e *fileEntry) read() ([]byte, error) {
+	f, err := os.Open(e.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() != e.size || !info.ModTime().Equal(e.modTime) {
+		return nil, fmt.Errorf("file '%s' changed since it was opened", e.name)
+	}
+
+	data := make([]byte, e.size)
+	if _, err = io.ReadFull(f, data); err != nil {
+		return nil, fmt.Errorf("read '%s': %w", e.name, err)
+	}
+	return data, nil
+}
+
+// Count returns number of files in the source.
+func (f *Files) Count() int {
+	return len(f.src.files)
+}
@@ -0,0 +1,113 @@
+package datagen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFiles(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0o700))
+	contents := map[string][]byte{
+		"a.txt":     []byte("a"),
+		"b.bin":     make([]byte, 1000),
+		"sub/c.log": []byte("ccc"),
+	}
+	for name, data := range contents {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), data, 0o600))
+	}
+
+	t.Run("sequential", func(t *testing.T) {
+		f, err := newFiles(&fileSources{}, dir, nil)
+		require.NoError(t, err)
+		require.Equal(t, 3, f.Count())
+
+		for _, name := range []string{"a.txt", "b.bin", "sub/c.log", "a.txt"} {
+			res := f.Next()
+			require.True(t, res.Success, res.Error)
+			require.Equal(t, name, res.Name)
+			require.Equal(t, contents[name], res.Payload.data)
+			require.Equal(t, len(contents[name]), res.Size)
+			h := sha256.Sum256(contents[name])
+			require.Equal(t, hex.EncodeToString(h[:]), res.Hash)
+		}
+	})
+
+	t.Run("weighted", func(t *testing.T) {
+		src, err := openFileSource(dir)
+		require.NoError(t, err)
+
+		counts := make(map[string]int)
+		for range 1000 {
+			counts[src.next(fileOrderWeighted).name]++
+		}
+		require.Greater(t, counts["b.bin"], 900)
+	})
+
+	t.Run("random", func(t *testing.T) {
+		src, err := openFileSource(dir)
+		require.NoError(t, err)
+
+		counts := make(map[string]int)
+		for range 1000 {
+			counts[src.next(fileOrderRandom).name]++
+		}
+		require.Len(t, counts, 3)
+	})
+
+	t.Run("single file", func(t *testing.T) {
+		src, err := openFileSource(filepath.Join(dir, "a.txt"))
+		require.NoError(t, err)
+		require.Len(t, src.files, 1)
+		require.Equal(t, "a.txt", src.files[0].name)
+	})
+
+	t.Run("shared", func(t *testing.T) {
+		var s fileSources
+		f1, err := newFiles(&s, dir, nil)
+		require.NoError(t, err)
+		f2, err := newFiles(&s, dir, map[string]string{"order": "random"})
+		require.NoError(t, err)
+		require.Same(t, f1.src, f2.src)
+		require.Equal(t, fileOrderRandom, f2.order)
+	})
+
+	t.Run("changed", func(t *testing.T) {
+		dir := t.TempDir()
+		for _, name := range []string{"a", "b"} {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(name), 0o600))
+		}
+		f, err := newFiles(&fileSources{}, dir, nil)
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "a"), []byte("changed"), 0o600))
+		require.NoError(t, os.Remove(filepath.Join(dir, "b")))
+
+		for _, name := range []string{"a", "b"} {
+			res := f.Next()
+			require.False(t, res.Success)
+			require.Equal(t, name, res.Name)
+			require.Nil(t, res.Payload)
+			require.NotEmpty(t, res.Error)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := newFiles(&fileSources{}, dir, map[string]string{"order": "unknown"})
+		require.Error(t, err)
+		_, err = openFileSource(t.TempDir())
+		require.Error(t, err)
+		_, err = openFileSource(filepath.Join(dir, "missing"))
+		require.Error(t, err)
+
+		empty := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(empty, "empty"), nil, 0o600))
+		_, err = newFiles(&fileSources{}, empty, map[string]string{"order": "weighted"})
+		require.Error(t, err)
+	})
+}
@@ -4,7 +4,9 @@ import (
 	"crypto/sha256"
 	"encoding/binary"
 	"encoding/hex"
+	"io"
 	"math/rand/v2"
+	"strconv"
 	"time"
 
 	"github.com/grafana/sobek"
@@ -20,16 +22,36 @@ type (
 	//   [<----------slice0-------->........]
 	//   [.<----------slice1-------->.......]
 	//   [..<----------slice2-------->......]
+	//
+	// Seeded generator fills the whole buffer anew for every payload from the
+	// seed derived for this payload, so that it can be regenerated later with
+	// the seed only.
+	//
+	// If size distribution is set, every payload has its own size chosen from
+	// the distribution and limited by the generator size.
 	Generator struct {
 		vu     modules.VU
 		size   int
 		buf    []byte
 		offset int
+
+		seeded bool
+		seed   uint64
+		count  uint64
+
+		dist sizeDistribution
+		rnd  *rand.Rand
+		mode payloadMode
 	}
 
 	GenPayloadResponse struct {
-		Payload sobek.ArrayBuffer
-		Hash    string
+		Payload   sobek.ArrayBuffer
+		Hash      string
+		MD5       string `js:"md5"`
+		TZ        string `js:"tz"`
+		Seed      string
+		Size      int
+		SizeClass string
 	}
 )
 
@@ -43,33 +65,145 @@ func NewGenerator(vu modules.VU, size int) Generator {
 	return Generator{vu: vu, size: size, buf: nil, offset: 0}
 }
 
-func (g *Generator) GenPayload(calcHash bool) GenPayloadResponse {
-	data := g.nextSlice()
+// NewSeededGenerator creates generator of reproducible payloads. Every payload
+// is generated from its own seed derived from the generator seed, VU ID and
+// iteration number.
+func NewSeededGenerator(vu modules.VU, size int, seed uint64) Generator {
+	g := NewGenerator(vu, size)
+	g.seeded = true
+	g.seed = seed
+	return g
+}
+
+// GenPayload returns the next payload. The hashes argument selects payload
+// hashes to calculate: it's either boolean (SHA-256 only) or a string or an
+// array with names of hash algorithms (sha256, md5, tz). Panics if an unknown
+// algorithm is requested.
+func (g *Generator) GenPayload(hashes sobek.Value) GenPayloadResponse {
+	hs, err := parseHashSet(hashes)
+	if err != nil {
+		panic(err)
+	}
+
+	var (
+		data    []byte
+		seedStr string
+	)
+	if g.seeded {
+		var seed uint64
+		data, seed = g.nextSeededSlice()
+		seedStr = strconv.FormatUint(seed, 10)
+	} else {
+		data = g.nextSlice()
+	}
 
-	dataHash := ""
-	if calcHash {
-		hashBytes := sha256.Sum256(data)
-		dataHash = hex.EncodeToString(hashBytes[:])
+	return g.response(data, hs, seedStr)
+}
+
+// Regenerate returns payload produced from the specified payload seed by
+// seeded generator with the same size parameters. The hashes argument is the
+// same as in GenPayload. Panics if seed is not a valid number.
+func (g *Generator) Regenerate(seed string, hashes sobek.Value) GenPayloadResponse {
+	s, err := strconv.ParseUint(seed, 10, 64)
+	if err != nil {
+		panic(err)
+	}
+	hs, err := parseHashSet(hashes)
+	if err != nil {
+		panic(err)
 	}
 
+	data := make([]byte, g.nextSize(rand.New(rand.NewPCG(s, 0))))
+	g.mode.fill(data, newChaCha8(s))
+
+	return g.response(data, hs, seed)
+}
+
+func (g *Generator) response(data []byte, hs hashSet, seed string) GenPayloadResponse {
+	sha256Sum, md5Sum, tzSum := hs.sum(data)
+
 	payload := g.vu.Runtime().NewArrayBuffer(data)
-	return GenPayloadResponse{Payload: payload, Hash: dataHash}
+	return GenPayloadResponse{
+		Payload:   payload,
+		Hash:      sha256Sum,
+		MD5:       md5Sum,
+		TZ:        tzSum,
+		Seed:      seed,
+		Size:      len(data),
+		SizeClass: sizeClass(len(data)),
+	}
+}
+
+// nextSize returns size of the next payload chosen from the generator
+// distribution with the specified source of randomness.
+func (g *Generator) nextSize(r *rand.Rand) int {
+	if g.dist == nil {
+		return g.size
+	}
+	return g.dist.next(r)
+}
+
+// NewStreamReader returns reader of size random bytes. Unlike Generator, it
+// doesn't keep the payload in memory, so it is suitable for huge objects.
+func NewStreamReader(size int64) io.Reader {
+	return NewSeededStreamReader(size, uint64(time.Now().UnixNano()))
+}
+
+// NewSeededStreamReader returns reader of size bytes of the payload generated
+// from the specified payload seed. It produces the same bytes as seeded
+// Generator in random mode does for the same seed.
+func NewSeededStreamReader(size int64, seed uint64) io.Reader {
+	return newModeStreamReader(payloadMode{}, size, seed)
+}
+
+func newModeStreamReader(mode payloadMode, size int64, seed uint64) io.Reader {
+	if size < 0 {
+		panic("size should not be negative")
+	}
+	return io.LimitReader(mode.reader(newChaCha8(seed)), size)
+}
+
+// PayloadHash returns hex-encoded SHA-256 hash of the payload of the specified
+// size generated from the payload seed in random mode.
+func PayloadHash(size int64, seed uint64) string {
+	return payloadHash(payloadMode{}, size, seed)
+}
+
+func payloadHash(mode payloadMode, size int64, seed uint64) string {
+	hasher := sha256.New()
+	_, _ = io.Copy(hasher, newModeStreamReader(mode, size, seed))
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// DeriveSeed returns payload seed for n-th payload generated in the specified
+// VU iteration by the generator with the base seed.
+func DeriveSeed(base, vuID uint64, iteration int64, n uint64) uint64 {
+	var buf [32]byte
+	binary.LittleEndian.PutUint64(buf[0:], base)
+	binary.LittleEndian.PutUint64(buf[8:], vuID)
+	binary.LittleEndian.PutUint64(buf[16:], uint64(iteration))
+	binary.LittleEndian.PutUint64(buf[24:], n)
+	h := sha256.Sum256(buf[:])
+	return binary.LittleEndian.Uint64(h[:])
+}
+
+func newChaCha8(seed uint64) *rand.ChaCha8 {
+	var s [32]byte
+	binary.LittleEndian.PutUint64(s[:], seed)
+	return rand.NewChaCha8(s)
 }
 
 func (g *Generator) nextSlice() []byte {
 	if g.buf == nil {
-		// Allocate buffer with extra tail for sliding and populate it with random bytes
+		// Allocate buffer with extra tail for sliding and populate it with payload bytes
 		g.buf = make([]byte, g.size+TailSize)
-
-		var seed [32]byte
-		binary.LittleEndian.PutUint64(seed[:], uint64(time.Now().UnixNano()))
-		chaCha8 := rand.NewChaCha8(seed)
-
-		//nolint:staticcheck
-		_, _ = chaCha8.Read(g.buf) // Per docs, err is always nil here
+		g.mode.fill(g.buf, newChaCha8(uint64(time.Now().UnixNano())))
 	}
 
-	result := g.buf[g.offset : g.offset+g.size]
+	if g.dist != nil && g.rnd == nil {
+		g.rnd = rand.New(rand.NewPCG(uint64(time.Now().UnixNano()), 0))
+	}
+	result := g.buf[g.offset : g.offset+g.nextSize(g.rnd)]
 
 	// Shift the offset for the next call. If we've used our entire tail, then erase
 	// the buffer so that on the next call it is regenerated anew
@@ -81,3 +215,26 @@ func (g *Generator) nextSlice() []byte {
 
 	return result
 }
+
+func (g *Generator) nextSeededSlice() ([]byte, uint64) {
+	var (
+		vuID      uint64
+		iteration int64
+	)
+	if state := g.vu.State(); state != nil {
+		vuID, iteration = state.VUIDGlobal, state.Iteration
+	}
+
+	seed := DeriveSeed(g.seed, vuID, iteration, g.count)
+	g.count++
+
+	if g.buf == nil {
+		g.buf = make([]byte, g.size)
+	}
+	// Size is chosen from the payload seed as well, so that payload can be
+	// regenerated completely.
+	data := g.buf[:g.nextSize(rand.New(rand.NewPCG(seed, 0)))]
+	g.mode.fill(data, newChaCha8(seed))
+
+	return data, seed
+}
@@ -1,6 +1,10 @@
 package datagen
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
 	"testing"
 
 	"github.com/grafana/sobek"
@@ -55,3 +59,55 @@ func TestGenerator(t *testing.T) {
 		assert.NotEqual(t, initialSlice, sliceAfterTail)
 	})
 }
+
+func TestSeededGenerator(t *testing.T) {
+	vu := &modulestest.VU{
+		RuntimeField: sobek.New(),
+	}
+
+	t.Run("creates a different slice on each call", func(t *testing.T) {
+		g := NewSeededGenerator(vu, 1000, 42)
+		slice1, seed1 := g.nextSeededSlice()
+		slice1 = bytes.Clone(slice1)
+		slice2, seed2 := g.nextSeededSlice()
+		assert.NotEqual(t, seed1, seed2)
+		assert.NotEqual(t, slice1, slice2)
+	})
+
+	t.Run("same seed produces same payloads", func(t *testing.T) {
+		g1 := NewSeededGenerator(vu, 1000, 42)
+		g2 := NewSeededGenerator(vu, 1000, 42)
+		for range 3 {
+			slice1, seed1 := g1.nextSeededSlice()
+			slice2, seed2 := g2.nextSeededSlice()
+			require.Equal(t, seed1, seed2)
+			require.Equal(t, slice1, slice2)
+		}
+	})
+
+	t.Run("payload can be regenerated from seed", func(t *testing.T) {
+		g := NewSeededGenerator(vu, 1000, 42)
+		slice, seed := g.nextSeededSlice()
+
+		regenerated, err := io.ReadAll(NewSeededStreamReader(1000, seed))
+		require.NoError(t, err)
+		require.Equal(t, slice, regenerated)
+
+		hash := sha256.Sum256(slice)
+		require.Equal(t, hex.EncodeToString(hash[:]), PayloadHash(1000, seed))
+	})
+}
+
+func TestStreamReader(t *testing.T) {
+	t.Run("fails on negative size", func(t *testing.T) {
+		require.Panics(t, func() {
+			_ = NewStreamReader(-1)
+		})
+	})
+
+	t.Run("reads specified number of bytes", func(t *testing.T) {
+		n, err := io.Copy(io.Discard, NewStreamReader(1<<20+1))
+		require.NoError(t, err)
+		require.EqualValues(t, 1<<20+1, n)
+	})
+}
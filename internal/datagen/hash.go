@@ -0,0 +1,96 @@
+package datagen
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/sobek"
+	"github.com/nspcc-dev/tzhash/tz"
+)
+
+// Payload hash algorithms supported by generator.
+const (
+	// HashSHA256 is SHA-256, it's also the NeoFS object payload checksum.
+	HashSHA256 = "sha256"
+	// HashMD5 is MD5, it's the S3 ETag of objects uploaded in a single part.
+	HashMD5 = "md5"
+	// HashTZ is Tillich-Zémor homomorphic hash of NeoFS object payload.
+	HashTZ = "tz"
+)
+
+// hashSet is a set of payload hash algorithms to calculate.
+type hashSet struct {
+	sha256, md5, tz bool
+}
+
+// parseHashSet parses hash algorithms requested from JS. The value can be
+// boolean (SHA-256 only, for compatibility), a comma-separated string or an
+// array of algorithm names.
+func parseHashSet(v sobek.Value) (hashSet, error) {
+	var hs hashSet
+	if v == nil || sobek.IsUndefined(v) || sobek.IsNull(v) {
+		return hs, nil
+	}
+
+	var names []string
+	switch exp := v.Export().(type) {
+	case bool:
+		hs.sha256 = exp
+		return hs, nil
+	case string:
+		names = strings.Split(exp, ",")
+	case []any:
+		for _, n := range exp {
+			s, ok := n.(string)
+			if !ok {
+				return hs, fmt.Errorf("invalid hash algorithm: '%v'", n)
+			}
+			names = append(names, s)
+		}
+	default:
+		return hs, fmt.Errorf("invalid hash algorithms: '%v'", exp)
+	}
+
+	return parseHashNames(names)
+}
+
+// parseHashNames returns set of the specified hash algorithms, empty names
+// are ignored.
+func parseHashNames(names []string) (hashSet, error) {
+	var hs hashSet
+	for _, n := range names {
+		switch strings.ToLower(strings.TrimSpace(n)) {
+		case HashSHA256:
+			hs.sha256 = true
+		case HashMD5:
+			hs.md5 = true
+		case HashTZ:
+			hs.tz = true
+		case "":
+		default:
+			return hs, fmt.Errorf("invalid hash algorithm: '%s'", n)
+		}
+	}
+	return hs, nil
+}
+
+// sum calculates hex-encoded hashes of data for all algorithms in the set,
+// hashes that are not requested are empty.
+func (hs hashSet) sum(data []byte) (sha256Sum, md5Sum, tzSum string) {
+	if hs.sha256 {
+		h := sha256.Sum256(data)
+		sha256Sum = hex.EncodeToString(h[:])
+	}
+	if hs.md5 {
+		h := md5.Sum(data)
+		md5Sum = hex.EncodeToString(h[:])
+	}
+	if hs.tz {
+		h := tz.Sum(data)
+		tzSum = hex.EncodeToString(h[:])
+	}
+	return sha256Sum, md5Sum, tzSum
+}
@@ -0,0 +1,61 @@
+package datagen
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/grafana/sobek"
+	"github.com/nspcc-dev/tzhash/tz"
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/js/modulestest"
+)
+
+func TestGenPayloadHashes(t *testing.T) {
+	rt := sobek.New()
+	vu := &modulestest.VU{RuntimeField: rt}
+	g := NewSeededGenerator(vu, 1024, 42)
+
+	payloadHashes := func(data []byte) (string, string, string) {
+		s := sha256.Sum256(data)
+		m := md5.Sum(data)
+		z := tz.Sum(data)
+		return hex.EncodeToString(s[:]), hex.EncodeToString(m[:]), hex.EncodeToString(z[:])
+	}
+
+	t.Run("boolean", func(t *testing.T) {
+		res := g.GenPayload(rt.ToValue(true))
+		sha, _, _ := payloadHashes(res.Payload.Bytes())
+		require.Equal(t, sha, res.Hash)
+		require.Empty(t, res.MD5)
+		require.Empty(t, res.TZ)
+
+		res = g.GenPayload(rt.ToValue(false))
+		require.Empty(t, res.Hash)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		for _, v := range []sobek.Value{
+			rt.ToValue("sha256,md5,tz"),
+			rt.ToValue([]any{"sha256", "MD5", "tz"}),
+		} {
+			res := g.GenPayload(v)
+			sha, m, z := payloadHashes(res.Payload.Bytes())
+			require.Equal(t, sha, res.Hash)
+			require.Equal(t, m, res.MD5)
+			require.Equal(t, z, res.TZ)
+		}
+	})
+
+	t.Run("regenerate", func(t *testing.T) {
+		res := g.GenPayload(rt.ToValue("md5"))
+		require.Empty(t, res.Hash)
+		require.Equal(t, res.MD5, g.Regenerate(res.Seed, rt.ToValue("md5")).MD5)
+	})
+
+	t.Run("unknown algorithm", func(t *testing.T) {
+		require.Panics(t, func() { g.GenPayload(rt.ToValue("crc32")) })
+		require.Panics(t, func() { g.GenPayload(rt.ToValue([]any{1})) })
+	})
+}
@@ -0,0 +1,201 @@
+package datagen
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"os"
+	"sync"
+)
+
+// Payload modes supported by generator.
+const (
+	modeRandom       = "random"
+	modeCompressible = "compressible"
+	modeText         = "text"
+	modeZeros        = "zeros"
+	modeCorpus       = "corpus"
+)
+
+const (
+	// compressibleBlockSize is a size of the block in compressible payload,
+	// every block consists of random prefix and zero suffix.
+	compressibleBlockSize = 256
+	// corpusSegmentSize is a size of the continuous corpus segment copied into
+	// payload before jumping to another random corpus position.
+	corpusSegmentSize = 4096
+)
+
+// defaultText is repeated in text payloads unless the text is specified.
+const defaultText = "Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod " +
+	"tempor incididunt ut labore et dolore magna aliqua. Ut enim ad minim veniam, quis nostrud " +
+	"exercitation ullamco laboris nisi ut aliquip ex ea commodo consequat.\n"
+
+type (
+	// payloadMode describes kind of generated payload bytes. Zero value
+	// generates random bytes.
+	payloadMode struct {
+		kind   string
+		ratio  float64
+		sample []byte
+	}
+
+	zeroReader struct{}
+
+	// cyclicReader repeats sample infinitely starting from the specified
+	// position. If segment is set, it jumps to the random position of the
+	// sample after every segment.
+	cyclicReader struct {
+		sample  []byte
+		pos     int
+		segment int
+		left    int
+		rnd     *rand.ChaCha8
+	}
+
+	// compressibleReader produces blocks with random prefix of the length
+	// that makes block compressible with the target ratio and zero suffix.
+	compressibleReader struct {
+		random int
+		pos    int
+		rnd    *rand.ChaCha8
+	}
+)
+
+var (
+	corpusMtx   sync.Mutex
+	corpusCache = make(map[string][]byte)
+)
+
+// parsePayloadMode creates payload mode from generator parameters.
+func parsePayloadMode(params map[string]string) (payloadMode, error) {
+	m := payloadMode{kind: modeRandom}
+	if kind, ok := params["mode"]; ok {
+		m.kind = kind
+	}
+
+	switch m.kind {
+	case modeRandom, modeZeros:
+	case modeCompressible:
+		ratio, err := parseFloatParam(params, "compression_ratio", 2)
+		if err != nil {
+			return m, err
+		}
+		if ratio < 1 {
+			return m, errors.New("'compression_ratio' should not be less than 1")
+		}
+		m.ratio = ratio
+	case modeText:
+		text := defaultText
+		if t, ok := params["text"]; ok {
+			text = t
+		}
+		if text == "" {
+			return m, errors.New("'text' should not be empty")
+		}
+		m.sample = []byte(text)
+	case modeCorpus:
+		corpus, err := loadCorpus(params["corpus"])
+		if err != nil {
+			return m, err
+		}
+		m.sample = corpus
+	default:
+		return m, fmt.Errorf("unknown payload mode: '%s'", m.kind)
+	}
+	return m, nil
+}
+
+// loadCorpus reads corpus file once and shares its contents between all
+// generators, corpus must not be modified.
+func loadCorpus(path string) ([]byte, error) {
+	corpusMtx.Lock()
+	defer corpusMtx.Unlock()
+
+	if corpus, ok := corpusCache[path]; ok {
+		return corpus, nil
+	}
+
+	corpus, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read corpus: %w", err)
+	}
+	if len(corpus) == 0 {
+		return nil, errors.New("corpus file is empty")
+	}
+	corpusCache[path] = corpus
+	return corpus, nil
+}
+
+// reader returns infinite stream of payload bytes. All randomness is taken
+// from rnd, so that the same payload is produced for the same seed regardless
+// of how the stream is read.
+func (m payloadMode) reader(rnd *rand.ChaCha8) io.Reader {
+	switch m.kind {
+	case modeZeros:
+		return zeroReader{}
+	case modeCompressible:
+		return &compressibleReader{
+			random: int(math.Round(compressibleBlockSize / m.ratio)),
+			rnd:    rnd,
+		}
+	case modeText:
+		return &cyclicReader{sample: m.sample, pos: int(rnd.Uint64() % uint64(len(m.sample)))}
+	case modeCorpus:
+		return &cyclicReader{sample: m.sample, segment: corpusSegmentSize, rnd: rnd}
+	default:
+		return rnd
+	}
+}
+
+// fill fills buffer with payload bytes.
+func (m payloadMode) fill(buf []byte, rnd *rand.ChaCha8) {
+	_, _ = io.ReadFull(m.reader(rnd), buf) // readers never fail
+}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func (r *cyclicReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if r.segment > 0 && r.left == 0 {
+			r.pos = int(r.rnd.Uint64() % uint64(len(r.sample)))
+			r.left = r.segment
+		}
+
+		chunk := r.sample[r.pos:]
+		if r.segment > 0 && len(chunk) > r.left {
+			chunk = chunk[:r.left]
+		}
+		c := copy(p[n:], chunk)
+		n += c
+		r.pos = (r.pos + c) % len(r.sample)
+		if r.segment > 0 {
+			r.left -= c
+		}
+	}
+	return n, nil
+}
+
+func (r *compressibleReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		c := 0
+		if r.pos < r.random {
+			c = min(len(p)-n, r.random-r.pos)
+			//nolint:staticcheck
+			_, _ = r.rnd.Read(p[n : n+c]) // Per docs, err is always nil here
+		} else {
+			c = min(len(p)-n, compressibleBlockSize-r.pos)
+			clear(p[n : n+c])
+		}
+		n += c
+		r.pos = (r.pos + c) % compressibleBlockSize
+	}
+	return n, nil
+}
@@ -0,0 +1,105 @@
+package datagen
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayloadMode(t *testing.T) {
+	corpus := filepath.Join(t.TempDir(), "corpus.txt")
+	require.NoError(t, os.WriteFile(corpus, bytes.Repeat([]byte("0123456789abcdef"), 1024), 0o600))
+
+	const size = 64 * 1024
+
+	t.Run("zeros", func(t *testing.T) {
+		m, err := parsePayloadMode(map[string]string{"mode": "zeros"})
+		require.NoError(t, err)
+		buf := bytes.Repeat([]byte{1}, size)
+		m.fill(buf, newChaCha8(1))
+		require.Equal(t, make([]byte, size), buf)
+	})
+
+	t.Run("text", func(t *testing.T) {
+		m, err := parsePayloadMode(map[string]string{"mode": "text", "text": "abc"})
+		require.NoError(t, err)
+		buf := make([]byte, 7)
+		m.fill(buf, newChaCha8(1))
+		require.Contains(t, "abcabcabcabc", string(buf))
+	})
+
+	t.Run("corpus", func(t *testing.T) {
+		m, err := parsePayloadMode(map[string]string{"mode": "corpus", "corpus": corpus})
+		require.NoError(t, err)
+		buf := make([]byte, size)
+		m.fill(buf, newChaCha8(1))
+		for i := 0; i < size; i += corpusSegmentSize {
+			require.Contains(t, string(m.sample)+string(m.sample), string(buf[i:i+corpusSegmentSize]))
+		}
+	})
+
+	t.Run("compressible", func(t *testing.T) {
+		for _, ratio := range []string{"1", "2", "4"} {
+			m, err := parsePayloadMode(map[string]string{"mode": "compressible", "compression_ratio": ratio})
+			require.NoError(t, err)
+			buf := make([]byte, size)
+			m.fill(buf, newChaCha8(1))
+
+			var compressed bytes.Buffer
+			w, err := flate.NewWriter(&compressed, flate.BestCompression)
+			require.NoError(t, err)
+			_, err = w.Write(buf)
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+
+			actual := float64(size) / float64(compressed.Len())
+			require.InDelta(t, m.ratio, actual, m.ratio*0.2, "ratio %s", ratio)
+		}
+	})
+
+	t.Run("stream is the same as buffer", func(t *testing.T) {
+		for _, params := range []map[string]string{
+			{},
+			{"mode": "compressible", "compression_ratio": "3"},
+			{"mode": "text"},
+			{"mode": "corpus", "corpus": corpus},
+		} {
+			m, err := parsePayloadMode(params)
+			require.NoError(t, err)
+
+			buf := make([]byte, size)
+			m.fill(buf, newChaCha8(42))
+
+			// read with small odd-sized chunks to cross block boundaries
+			stream, err := io.ReadAll(io.LimitReader(onlyReader{m.reader(newChaCha8(42))}, size))
+			require.NoError(t, err)
+			require.Equal(t, buf, stream, params)
+		}
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		for _, params := range []map[string]string{
+			{"mode": "unknown"},
+			{"mode": "compressible", "compression_ratio": "0.5"},
+			{"mode": "text", "text": ""},
+			{"mode": "corpus", "corpus": filepath.Join(t.TempDir(), "missing")},
+		} {
+			_, err := parsePayloadMode(params)
+			require.Error(t, err, params)
+		}
+	})
+}
+
+// onlyReader reads at most 777 bytes at once.
+type onlyReader struct {
+	r io.Reader
+}
+
+func (o onlyReader) Read(p []byte) (int, error) {
+	return o.r.Read(p[:min(len(p), 777)])
+}
@@ -0,0 +1,253 @@
+package datagen
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.k6.io/k6/js/modules"
+)
+
+type (
+	// KeyGenerator generates object keys (names) with directory-like prefixes.
+	// Every key has depth directories, every directory has fanout
+	// subdirectories, so there are fanout^depth leaf directories in total:
+	//
+	//   prefix/03/11/<name>
+	//
+	// Random keys are placed into random directories and have random names.
+	// Sequential keys have increasing numbers as names and fill leaf
+	// directories round-robin, the counter is shared by all VUs, so that
+	// every key is generated once.
+	KeyGenerator struct {
+		vu         modules.VU
+		prefix     string
+		depth      int
+		fanout     int
+		sequential bool
+		seed       uint64
+		seeded     bool
+
+		rnd     *rand.Rand
+		counter *atomic.Uint64
+	}
+
+	// keyCounters stores counters of sequential key generators by name.
+	keyCounters struct {
+		mu sync.Mutex
+		m  map[string]*atomic.Uint64
+	}
+
+	// AttributeGenerator generates attribute maps with fixed keys. Every
+	// attribute has cardinality distinct values chosen either uniformly or
+	// with Zipf distribution, so that small values are the most frequent.
+	AttributeGenerator struct {
+		vu          modules.VU
+		keys        []string
+		cardinality int
+		zipfS       float64
+		zipfV       float64
+		zipf        bool
+		seed        uint64
+		seeded      bool
+
+		rnd   *rand.Rand
+		zipfs []*rand.Zipf
+	}
+)
+
+// get returns the counter with the specified name creating it if it doesn't
+// exist yet.
+func (c *keyCounters) get(name string) *atomic.Uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	counter, ok := c.m[name]
+	if !ok {
+		if c.m == nil {
+			c.m = make(map[string]*atomic.Uint64)
+		}
+		counter = new(atomic.Uint64)
+		c.m[name] = counter
+	}
+	return counter
+}
+
+func newKeyGenerator(vu modules.VU, params map[string]string, counters *keyCounters) (*KeyGenerator, error) {
+	g := &KeyGenerator{vu: vu, prefix: params["prefix"]}
+
+	var err error
+	if g.depth, err = parseIntParam(params, "depth", 0); err != nil {
+		return nil, err
+	}
+	if g.depth < 0 {
+		return nil, errors.New("'depth' should not be negative")
+	}
+	if g.fanout, err = parseIntParam(params, "fanout", 16); err != nil {
+		return nil, err
+	}
+	if g.fanout <= 0 {
+		return nil, errors.New("'fanout' should be positive")
+	}
+
+	switch naming := params["naming"]; naming {
+	case "", "random":
+	case "sequential":
+		g.sequential = true
+		name, ok := params["name"]
+		if !ok {
+			name = g.prefix
+		}
+		g.counter = counters.get(name)
+	default:
+		return nil, fmt.Errorf("unknown naming: '%s'", naming)
+	}
+
+	if g.seed, g.seeded, err = parseSeedParam(params); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Next returns the next object key.
+func (g *KeyGenerator) Next() string {
+	var b strings.Builder
+	b.WriteString(g.prefix)
+
+	width := len(strconv.Itoa(g.fanout - 1))
+	if g.sequential {
+		n := g.counter.Add(1) - 1
+		dir := n
+		dirs := make([]uint64, g.depth)
+		for i := range dirs {
+			dirs[g.depth-1-i] = dir % uint64(g.fanout)
+			dir /= uint64(g.fanout)
+		}
+		for _, d := range dirs {
+			fmt.Fprintf(&b, "%0*d/", width, d)
+		}
+		fmt.Fprintf(&b, "%010d", n)
+		return b.String()
+	}
+
+	r := g.rand()
+	for range g.depth {
+		fmt.Fprintf(&b, "%0*d/", width, r.IntN(g.fanout))
+	}
+	fmt.Fprintf(&b, "%016x%016x", r.Uint64(), r.Uint64())
+	return b.String()
+}
+
+func (g *KeyGenerator) rand() *rand.Rand {
+	if g.rnd == nil {
+		g.rnd = newVURand(g.vu, g.seed, g.seeded)
+	}
+	return g.rnd
+}
+
+func newAttributeGenerator(vu modules.VU, params map[string]string) (*AttributeGenerator, error) {
+	g := &AttributeGenerator{vu: vu}
+
+	for k := range strings.SplitSeq(params["keys"], ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			g.keys = append(g.keys, k)
+		}
+	}
+	if len(g.keys) == 0 {
+		return nil, errors.New("'keys' should not be empty")
+	}
+
+	var err error
+	if g.cardinality, err = parseIntParam(params, "cardinality", 100); err != nil {
+		return nil, err
+	}
+	if g.cardinality <= 0 {
+		return nil, errors.New("'cardinality' should be positive")
+	}
+
+	switch dist := params["distribution"]; dist {
+	case "", "uniform":
+	case "zipf":
+		g.zipf = true
+		if g.zipfS, err = parseFloatParam(params, "zipf_s", 1.1); err != nil {
+			return nil, err
+		}
+		if g.zipfS <= 1 {
+			return nil, errors.New("'zipf_s' should be greater than 1")
+		}
+		if g.zipfV, err = parseFloatParam(params, "zipf_v", 1); err != nil {
+			return nil, err
+		}
+		if g.zipfV < 1 {
+			return nil, errors.New("'zipf_v' should not be less than 1")
+		}
+	default:
+		return nil, fmt.Errorf("unknown distribution: '%s'", dist)
+	}
+
+	if g.seed, g.seeded, err = parseSeedParam(params); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Next returns the next attribute map. Values are numbers in
+// [0, cardinality) range.
+func (g *AttributeGenerator) Next() map[string]string {
+	if g.rnd == nil {
+		g.rnd = newVURand(g.vu, g.seed, g.seeded)
+		if g.zipf {
+			// Every attribute has its own Zipf generator, so that values of
+			// different attributes are independent.
+			g.zipfs = make([]*rand.Zipf, len(g.keys))
+			for i := range g.zipfs {
+				g.zipfs[i] = rand.NewZipf(g.rnd, g.zipfS, g.zipfV, uint64(g.cardinality-1))
+			}
+		}
+	}
+
+	attrs := make(map[string]string, len(g.keys))
+	for i, k := range g.keys {
+		var v uint64
+		if g.zipf {
+			v = g.zipfs[i].Uint64()
+		} else {
+			v = g.rnd.Uint64N(uint64(g.cardinality))
+		}
+		attrs[k] = strconv.FormatUint(v, 10)
+	}
+	return attrs
+}
+
+func parseSeedParam(params map[string]string) (uint64, bool, error) {
+	s, ok := params["seed"]
+	if !ok {
+		return 0, false, nil
+	}
+	seed, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid value for 'seed': '%s'", s)
+	}
+	return seed, true, nil
+}
+
+// newVURand returns source of randomness for the current VU. Seeded source
+// produces the same sequence for the same seed and VU ID, but different VUs
+// get different sequences.
+func newVURand(vu modules.VU, seed uint64, seeded bool) *rand.Rand {
+	if !seeded {
+		return rand.New(rand.NewPCG(uint64(time.Now().UnixNano()), 0))
+	}
+	var vuID uint64
+	if vu != nil {
+		if state := vu.State(); state != nil {
+			vuID = state.VUIDGlobal
+		}
+	}
+	return rand.New(rand.NewPCG(DeriveSeed(seed, vuID, 0, 0), 0))
+}
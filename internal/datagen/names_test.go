@@ -0,0 +1,137 @@
+package datagen
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+)
+
+func TestKeyGenerator(t *testing.T) {
+	t.Run("random", func(t *testing.T) {
+		g, err := newKeyGenerator(nil, map[string]string{"prefix": "p/", "depth": "2", "fanout": "12"}, &keyCounters{})
+		require.NoError(t, err)
+
+		keys := make(map[string]struct{})
+		for range 1000 {
+			key := g.Next()
+			parts := strings.Split(key, "/")
+			require.Len(t, parts, 4, key)
+			require.Equal(t, "p", parts[0])
+			for _, dir := range parts[1:3] {
+				require.Len(t, dir, 2)
+				n, err := strconv.Atoi(dir)
+				require.NoError(t, err)
+				require.Less(t, n, 12)
+			}
+			require.Len(t, parts[3], 32)
+			keys[key] = struct{}{}
+		}
+		require.Len(t, keys, 1000)
+	})
+
+	t.Run("sequential", func(t *testing.T) {
+		g, err := newKeyGenerator(nil, map[string]string{"depth": "2", "fanout": "3", "naming": "sequential"}, &keyCounters{})
+		require.NoError(t, err)
+		require.Equal(t, "0/0/0000000000", g.Next())
+		require.Equal(t, "0/1/0000000001", g.Next())
+		require.Equal(t, "0/2/0000000002", g.Next())
+		require.Equal(t, "1/0/0000000003", g.Next())
+	})
+
+	t.Run("different VUs", func(t *testing.T) {
+		root := new(RootModule)
+		vus := []*Datagen{
+			root.NewModuleInstance(&modulestest.VU{StateField: &lib.State{VUIDGlobal: 1}}).(*Datagen),
+			root.NewModuleInstance(&modulestest.VU{StateField: &lib.State{VUIDGlobal: 2}}).(*Datagen),
+		}
+		for _, params := range []map[string]string{
+			{"prefix": "seq/", "depth": "1", "naming": "sequential"},
+			{"prefix": "rnd/", "depth": "1", "seed": "42"},
+		} {
+			gens := []*KeyGenerator{vus[0].KeyGenerator(params), vus[1].KeyGenerator(params)}
+			keys := make(map[string]struct{})
+			for range 100 {
+				for _, g := range gens {
+					keys[g.Next()] = struct{}{}
+				}
+			}
+			require.Len(t, keys, 200, params)
+		}
+	})
+
+	t.Run("seeded", func(t *testing.T) {
+		params := map[string]string{"depth": "1", "seed": "42"}
+		g1, err := newKeyGenerator(nil, params, &keyCounters{})
+		require.NoError(t, err)
+		g2, err := newKeyGenerator(nil, params, &keyCounters{})
+		require.NoError(t, err)
+		for range 10 {
+			require.Equal(t, g1.Next(), g2.Next())
+		}
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		for _, params := range []map[string]string{
+			{"depth": "-1"},
+			{"fanout": "0"},
+			{"naming": "unknown"},
+			{"seed": "x"},
+		} {
+			_, err := newKeyGenerator(nil, params, &keyCounters{})
+			require.Error(t, err, params)
+		}
+	})
+}
+
+func TestAttributeGenerator(t *testing.T) {
+	t.Run("uniform", func(t *testing.T) {
+		g, err := newAttributeGenerator(nil, map[string]string{"keys": "a, b", "cardinality": "5"})
+		require.NoError(t, err)
+
+		values := make(map[string]struct{})
+		for range 1000 {
+			attrs := g.Next()
+			require.Len(t, attrs, 2)
+			for _, k := range []string{"a", "b"} {
+				v, err := strconv.Atoi(attrs[k])
+				require.NoError(t, err)
+				require.Less(t, v, 5)
+				values[attrs[k]] = struct{}{}
+			}
+		}
+		require.Len(t, values, 5)
+	})
+
+	t.Run("zipf", func(t *testing.T) {
+		g, err := newAttributeGenerator(nil, map[string]string{"keys": "a", "cardinality": "1000", "distribution": "zipf", "seed": "1"})
+		require.NoError(t, err)
+
+		counts := make(map[int]int)
+		for range 10000 {
+			v, err := strconv.Atoi(g.Next()["a"])
+			require.NoError(t, err)
+			require.Less(t, v, 1000)
+			counts[v]++
+		}
+		// the smallest values are the most frequent
+		require.Greater(t, counts[0], counts[1])
+		require.Greater(t, counts[1], counts[10])
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		for _, params := range []map[string]string{
+			{},
+			{"keys": "a", "cardinality": "0"},
+			{"keys": "a", "distribution": "unknown"},
+			{"keys": "a", "distribution": "zipf", "zipf_s": "1"},
+			{"keys": "a", "distribution": "zipf", "zipf_v": "0.5"},
+		} {
+			_, err := newAttributeGenerator(nil, params)
+			require.Error(t, err, params)
+		}
+	})
+}
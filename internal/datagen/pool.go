@@ -0,0 +1,144 @@
+package datagen
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/grafana/sobek"
+)
+
+type (
+	// Pool is a read-only set of payload buffers shared by all VUs. Payloads
+	// are slices of the buffers with an increasing offset, like Generator
+	// produces, but buffers are generated only once and payloads are never
+	// copied into JS heap, so pool memory doesn't depend on the number of VUs.
+	// Payloads repeat after every buffers*TailSize calls.
+	Pool struct {
+		size    int
+		buffers [][]byte
+		counter atomic.Uint64
+	}
+
+	// Payload is a handle of the payload from the shared pool. It can be passed
+	// to put operations of native, S3 and HTTP clients instead of ArrayBuffer.
+	Payload struct {
+		data []byte
+	}
+)
+
+// pools stores shared payload pools by name.
+type pools struct {
+	mu sync.Mutex
+	m  map[string]*Pool
+}
+
+// get returns the pool with the specified name creating it if it doesn't
+// exist yet.
+func (p *pools) get(name string, size int, params map[string]string) (*Pool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if pool, ok := p.m[name]; ok {
+		if pool.size != size {
+			return nil, fmt.Errorf("pool '%s' already exists with size %d", name, pool.size)
+		}
+		return pool, nil
+	}
+
+	pool, err := newPool(size, params)
+	if err != nil {
+		return nil, err
+	}
+	if p.m == nil {
+		p.m = make(map[string]*Pool)
+	}
+	p.m[name] = pool
+	return pool, nil
+}
+
+func newPool(size int, params map[string]string) (*Pool, error) {
+	if size < 0 {
+		return nil, fmt.Errorf("invalid pool size: %d", size)
+	}
+
+	buffers := 1
+	if s, ok := params["buffers"]; ok {
+		var err error
+		if buffers, err = strconv.Atoi(s); err != nil || buffers <= 0 {
+			return nil, fmt.Errorf("invalid value for 'buffers': '%s'", s)
+		}
+	}
+
+	mode, err := parsePayloadMode(params)
+	if err != nil {
+		return nil, err
+	}
+
+	seed := uint64(time.Now().UnixNano())
+	if s, ok := params["seed"]; ok {
+		if seed, err = strconv.ParseUint(s, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid value for 'seed': '%s'", s)
+		}
+	}
+
+	pool := &Pool{size: size, buffers: make([][]byte, buffers)}
+	for i := range pool.buffers {
+		pool.buffers[i] = make([]byte, size+TailSize)
+		mode.fill(pool.buffers[i], newChaCha8(seed+uint64(i)))
+	}
+	return pool, nil
+}
+
+// Next returns handle of the next payload from the pool. It is safe for
+// concurrent use.
+func (p *Pool) Next() *Payload {
+	n := p.counter.Add(1) - 1
+	buf := p.buffers[n%uint64(len(p.buffers))]
+	offset := int(n / uint64(len(p.buffers)) % TailSize)
+	return &Payload{data: buf[offset : offset+p.size : offset+p.size]}
+}
+
+// Size returns size of the pool payloads.
+func (p *Pool) Size() int {
+	return p.size
+}
+
+// Size returns payload size.
+func (p *Payload) Size() int {
+	return len(p.data)
+}
+
+// Hash returns hex-encoded hash of the payload calculated with the specified
+// algorithm (sha256, md5 or tz), SHA-256 is used by default. Panics if the
+// algorithm is unknown.
+func (p *Payload) Hash(algorithm string) string {
+	if algorithm == "" {
+		algorithm = HashSHA256
+	}
+	hs, err := parseHashNames([]string{algorithm})
+	if err != nil {
+		panic(err)
+	}
+	sha256Sum, md5Sum, tzSum := hs.sum(p.data)
+	return sha256Sum + md5Sum + tzSum
+}
+
+// PayloadBytes returns payload passed from JS either as ArrayBuffer or as
+// Payload handle of the shared pool. Payload bytes are not copied, so they
+// must not be modified.
+func PayloadBytes(v sobek.Value) ([]byte, error) {
+	if v == nil {
+		return nil, fmt.Errorf("missing payload")
+	}
+	switch p := v.Export().(type) {
+	case sobek.ArrayBuffer:
+		return p.Bytes(), nil
+	case *Payload:
+		return p.data, nil
+	default:
+		return nil, fmt.Errorf("invalid payload type: %T", p)
+	}
+}
@@ -0,0 +1,93 @@
+package datagen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"testing"
+
+	"github.com/grafana/sobek"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool(t *testing.T) {
+	t.Run("shared by name", func(t *testing.T) {
+		var ps pools
+		p1, err := ps.get("a", 100, nil)
+		require.NoError(t, err)
+		p2, err := ps.get("a", 100, map[string]string{"buffers": "4"})
+		require.NoError(t, err)
+		require.Same(t, p1, p2)
+
+		_, err = ps.get("a", 200, nil)
+		require.Error(t, err)
+
+		p3, err := ps.get("b", 200, nil)
+		require.NoError(t, err)
+		require.NotSame(t, p1, p3)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		for _, params := range []map[string]string{
+			{"buffers": "0"},
+			{"seed": "x"},
+			{"mode": "unknown"},
+		} {
+			_, err := newPool(10, params)
+			require.Error(t, err, params)
+		}
+	})
+
+	t.Run("payloads", func(t *testing.T) {
+		p, err := newPool(1000, map[string]string{"buffers": "2", "seed": "1"})
+		require.NoError(t, err)
+
+		var (
+			wg   sync.WaitGroup
+			mu   sync.Mutex
+			seen = make(map[string]struct{})
+		)
+		for range 4 {
+			wg.Go(func() {
+				for range TailSize / 2 {
+					pl := p.Next()
+					require.Equal(t, 1000, pl.Size())
+					mu.Lock()
+					seen[string(pl.data)] = struct{}{}
+					mu.Unlock()
+				}
+			})
+		}
+		wg.Wait()
+		require.Len(t, seen, 2*TailSize)
+
+		// payloads repeat after all slices are used
+		require.Equal(t, p.buffers[0][:1000], p.Next().data)
+	})
+
+	t.Run("hash", func(t *testing.T) {
+		p, err := newPool(100, nil)
+		require.NoError(t, err)
+		pl := p.Next()
+		h := sha256.Sum256(pl.data)
+		require.Equal(t, hex.EncodeToString(h[:]), pl.Hash(""))
+		require.Len(t, pl.Hash("md5"), 32)
+		require.Panics(t, func() { pl.Hash("crc32") })
+	})
+}
+
+func TestPayloadBytes(t *testing.T) {
+	rt := sobek.New()
+
+	data, err := PayloadBytes(rt.ToValue(rt.NewArrayBuffer([]byte{1, 2, 3})))
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3}, data)
+
+	pl := &Payload{data: []byte{4, 5}}
+	data, err = PayloadBytes(rt.ToValue(pl))
+	require.NoError(t, err)
+	require.Equal(t, pl.data, data)
+
+	_, err = PayloadBytes(rt.ToValue("payload"))
+	require.Error(t, err)
+}
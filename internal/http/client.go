@@ -0,0 +1,263 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/sobek"
+	"github.com/nspcc-dev/xk6-neofs/internal/datagen"
+	"github.com/nspcc-dev/xk6-neofs/internal/stats"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/metrics"
+)
+
+type (
+	Client struct {
+		vu  modules.VU
+		cli *http.Client
+		// headCli is used only by Head, its connections record raw
+		// response headers.
+		headCli  *http.Client
+		endpoint string
+	}
+
+	UploadResponse struct {
+		Success  bool
+		ObjectID string
+		Error    string
+	}
+
+	DownloadResponse struct {
+		Success bool
+		Error   string
+	}
+
+	HeadResponse struct {
+		Success    bool
+		Size       int64
+		Attributes map[string]string
+		Error      string
+	}
+
+	VerifyHashResponse struct {
+		Success bool
+		Error   string
+	}
+)
+
+// attributeHeaderPrefix is a prefix of HTTP headers that gateway maps to
+// object attributes.
+const attributeHeaderPrefix = "X-Attribute-"
+
+// Upload puts object into the container with multipart form request, as
+// browsers do. The form is streamed to the gateway, so payload isn't copied.
+// The attributes are sent as X-Attribute-* headers.
+func (c *Client) Upload(containerID, filename string, payload sobek.Value, attributes map[string]string) UploadResponse {
+	data, err := datagen.PayloadBytes(payload)
+	if err != nil {
+		panic(err)
+	}
+
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		part, err := mw.CreateFormFile("file", filename)
+		if err == nil {
+			_, err = part.Write(data)
+		}
+		if err == nil {
+			err = mw.Close()
+		}
+		_ = pw.CloseWithError(err)
+	}()
+	// Unblock the writer if request fails before the whole body is read.
+	defer pr.Close()
+
+	return c.upload(containerID, mw.FormDataContentType(), pr, int64(len(data)), attributes)
+}
+
+// UploadRaw puts object into the container sending payload as request body.
+// The attributes are sent as X-Attribute-* headers.
+func (c *Client) UploadRaw(containerID string, payload sobek.Value, attributes map[string]string) UploadResponse {
+	data, err := datagen.PayloadBytes(payload)
+	if err != nil {
+		panic(err)
+	}
+	return c.upload(containerID, "application/octet-stream", bytes.NewReader(data), int64(len(data)), attributes)
+}
+
+func (c *Client) upload(containerID, contentType string, body io.Reader, sz int64, attributes map[string]string) UploadResponse {
+	stats.Report(c.vu, objUploadTotal, 1)
+	start := time.Now()
+
+	req, err := http.NewRequestWithContext(c.vu.Context(), http.MethodPost,
+		c.endpoint+"/upload/"+url.PathEscape(containerID), body)
+	if err != nil {
+		stats.Report(c.vu, objUploadFails, 1)
+		return UploadResponse{Success: false, Error: err.Error()}
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range attributes {
+		req.Header.Set(attributeHeaderPrefix+k, v)
+	}
+
+	resp, err := c.cli.Do(req)
+	if err != nil {
+		stats.Report(c.vu, objUploadFails, 1)
+		return UploadResponse{Success: false, Error: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	if err = checkStatus(resp); err != nil {
+		stats.Report(c.vu, objUploadFails, 1)
+		return UploadResponse{Success: false, Error: err.Error()}
+	}
+
+	var res struct {
+		ObjectID string `json:"object_id"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		stats.Report(c.vu, objUploadFails, 1)
+		return UploadResponse{Success: false, Error: fmt.Sprintf("decode response: %s", err)}
+	}
+
+	stats.ReportDataSent(c.vu, float64(sz))
+	stats.Report(c.vu, objUploadDuration, metrics.D(time.Since(start)))
+	return UploadResponse{Success: true, ObjectID: res.ObjectID}
+}
+
+func (c *Client) Download(containerID, objectID string) DownloadResponse {
+	return c.download(c.objectURL(containerID, objectID))
+}
+
+func (c *Client) DownloadByAttribute(containerID, key, value string) DownloadResponse {
+	return c.download(c.endpoint + "/get_by_attribute/" + url.PathEscape(containerID) + "/" +
+		url.PathEscape(key) + "/" + url.PathEscape(value))
+}
+
+func (c *Client) download(u string) DownloadResponse {
+	stats.Report(c.vu, objDownloadTotal, 1)
+	start := time.Now()
+
+	objSize, err := c.get(u, io.Discard)
+	if err != nil {
+		stats.Report(c.vu, objDownloadFails, 1)
+		return DownloadResponse{Success: false, Error: err.Error()}
+	}
+
+	stats.Report(c.vu, objDownloadDuration, metrics.D(time.Since(start)))
+	stats.ReportDataReceived(c.vu, float64(objSize))
+	return DownloadResponse{Success: true}
+}
+
+func (c *Client) Head(containerID, objectID string) HeadResponse {
+	stats.Report(c.vu, objHeadTotal, 1)
+	start := time.Now()
+
+	var conn *headerConn
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if hc, ok := info.Conn.(*headerConn); ok {
+				hc.record()
+				conn = hc
+			}
+		},
+	}
+
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(c.vu.Context(), trace), http.MethodHead,
+		c.objectURL(containerID, objectID), nil)
+	if err != nil {
+		stats.Report(c.vu, objHeadFails, 1)
+		return HeadResponse{Success: false, Error: err.Error()}
+	}
+
+	resp, err := c.headCli.Do(req)
+	if err != nil {
+		stats.Report(c.vu, objHeadFails, 1)
+		return HeadResponse{Success: false, Error: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	if err = checkStatus(resp); err != nil {
+		stats.Report(c.vu, objHeadFails, 1)
+		return HeadResponse{Success: false, Error: err.Error()}
+	}
+
+	stats.Report(c.vu, objHeadDuration, metrics.D(time.Since(start)))
+
+	var raw []byte
+	if conn != nil {
+		raw = conn.recorded()
+	}
+
+	var attrs map[string]string
+	if raw != nil {
+		attrs = rawAttributes(raw)
+	} else {
+		// raw headers are unavailable, names are canonicalized
+		attrs = make(map[string]string)
+		for k, v := range resp.Header {
+			if name, ok := strings.CutPrefix(k, attributeHeaderPrefix); ok && len(v) > 0 {
+				attrs[name] = v[0]
+			}
+		}
+	}
+	size, _ := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	return HeadResponse{Success: true, Size: size, Attributes: attrs}
+}
+
+func (c *Client) VerifyHash(containerID, objectID, expectedHash string) VerifyHashResponse {
+	hasher := sha256.New()
+	_, err := c.get(c.objectURL(containerID, objectID), hasher)
+	if err != nil {
+		return VerifyHashResponse{Success: false, Error: err.Error()}
+	}
+	actualHash := hex.EncodeToString(hasher.Sum(nil))
+	if actualHash != expectedHash {
+		return VerifyHashResponse{Success: false, Error: "hash mismatch"}
+	}
+
+	return VerifyHashResponse{Success: true}
+}
+
+func (c *Client) objectURL(containerID, objectID string) string {
+	return c.endpoint + "/get/" + url.PathEscape(containerID) + "/" + url.PathEscape(objectID)
+}
+
+func (c *Client) get(u string, w io.Writer) (int64, error) {
+	req, err := http.NewRequestWithContext(c.vu.Context(), http.MethodGet, u, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := c.cli.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if err = checkStatus(resp); err != nil {
+		return 0, err
+	}
+
+	return io.Copy(w, resp.Body)
+}
+
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+}
@@ -0,0 +1,141 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/sobek"
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+func newTestClient(t *testing.T, endpoint string) (*Client, *sobek.Runtime) {
+	registry := metrics.NewRegistry()
+	rt := sobek.New()
+	vu := &modulestest.VU{
+		CtxField:     context.Background(),
+		RuntimeField: rt,
+		StateField: &lib.State{
+			Samples:        make(chan metrics.SampleContainer, 1000),
+			Tags:           lib.NewVUStateTags(registry.RootTagSet()),
+			BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+		},
+	}
+
+	cli, err := (&HTTP{vu: vu}).Connect(endpoint, map[string]string{"no_verify_ssl": "true"})
+	require.NoError(t, err)
+	return cli, rt
+}
+
+func TestUpload(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/upload/cid" || r.Header.Get("X-Attribute-FileName") != "cat.jpg" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		f, header, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(f)
+		if header.Filename != "cat.jpg" || string(data) != "hello world" {
+			http.Error(w, "unexpected file", http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"object_id": "oid"})
+	}))
+	defer srv.Close()
+
+	cli, rt := newTestClient(t, srv.URL)
+	payload := rt.ToValue(rt.NewArrayBuffer([]byte("hello world")))
+
+	resp := cli.Upload("cid", "cat.jpg", payload, map[string]string{"FileName": "cat.jpg"})
+	require.True(t, resp.Success, resp.Error)
+	require.Equal(t, "oid", resp.ObjectID)
+
+	resp = cli.Upload("other", "cat.jpg", payload, nil)
+	require.False(t, resp.Success)
+	require.Contains(t, resp.Error, "unexpected status 400")
+}
+
+func TestHead(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/get/cid/oid" {
+			http.NotFound(w, r)
+			return
+		}
+		// Non-canonical names are written as is.
+		w.Header()["X-Attribute-FileName"] = []string{"cat.jpg"}
+		w.Header()["X-Attribute-__NEOFS__EXPIRATION_EPOCH"] = []string{"100"}
+		w.Header().Set("Content-Length", "42")
+	})
+
+	for name, newServer := range map[string]func(http.Handler) *httptest.Server{
+		"http":  httptest.NewServer,
+		"https": httptest.NewTLSServer,
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv := newServer(handler)
+			defer srv.Close()
+
+			cli, _ := newTestClient(t, srv.URL)
+
+			// The second request reuses connection.
+			for range 2 {
+				resp := cli.Head("cid", "oid")
+				require.True(t, resp.Success, resp.Error)
+				require.EqualValues(t, 42, resp.Size)
+				require.Equal(t, map[string]string{
+					"FileName":                  "cat.jpg",
+					"__NEOFS__EXPIRATION_EPOCH": "100",
+				}, resp.Attributes)
+			}
+
+			resp := cli.Head("cid", "missing")
+			require.False(t, resp.Success)
+
+			// Other requests don't use connections of Head.
+			require.Nil(t, cli.cli.Transport.(*http.Transport).DialContext)
+		})
+	}
+}
+
+func TestVerifyHash(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello world"))
+	}))
+	defer srv.Close()
+
+	cli, _ := newTestClient(t, srv.URL)
+	hash := sha256.Sum256([]byte("hello world"))
+
+	resp := cli.VerifyHash("cid", "oid", hex.EncodeToString(hash[:]))
+	require.True(t, resp.Success, resp.Error)
+
+	resp = cli.VerifyHash("cid", "oid", "deadbeef")
+	require.False(t, resp.Success)
+	require.Equal(t, "hash mismatch", resp.Error)
+}
+
+func TestRawAttributes(t *testing.T) {
+	header := []byte("HTTP/1.1 200 OK\r\n" +
+		"Content-Length: 42\r\n" +
+		"x-attribute-lower: a\r\n" +
+		"X-Attribute-CamelCase:  b \r\n" +
+		"X-Attribute-CamelCase: c\r\n" +
+		"X-Attribute-: empty")
+
+	require.Equal(t, map[string]string{
+		"lower":     "a",
+		"CamelCase": "b",
+	}, rawAttributes(header))
+}
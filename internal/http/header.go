@@ -0,0 +1,116 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// headerConn records raw header block of the next response read from the
+// connection. net/http canonicalizes header names, so the original case of
+// NeoFS attributes sent as X-Attribute-* headers can only be taken from the
+// raw response.
+type headerConn struct {
+	net.Conn
+
+	mu        sync.Mutex
+	recording bool
+	header    []byte
+}
+
+var headerEnd = []byte("\r\n\r\n")
+
+func (c *headerConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+
+	c.mu.Lock()
+	if c.recording && n > 0 {
+		c.header = append(c.header, b[:n]...)
+		if i := bytes.Index(c.header, headerEnd); i >= 0 {
+			c.header = c.header[:i]
+			c.recording = false
+		}
+	}
+	c.mu.Unlock()
+
+	return n, err
+}
+
+// record starts recording of the next response header block. It must be
+// called before the request is sent.
+func (c *headerConn) record() {
+	c.mu.Lock()
+	c.recording = true
+	c.header = nil
+	c.mu.Unlock()
+}
+
+// recorded returns raw header block of the response or nil if it hasn't been
+// read completely.
+func (c *headerConn) recorded() []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.recording {
+		return nil
+	}
+	return c.header
+}
+
+// newHeadTransport creates HTTP/1.1 transport with connections that are able
+// to record raw response headers, it's used for HEAD requests only. TLS
+// connections are established by the transport itself, so that headers are
+// recorded after decryption.
+func newHeadTransport(tlsConfig *tls.Config) *http.Transport {
+	var dialer net.Dialer
+
+	return &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := dialer.DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			return &headerConn{Conn: conn}, nil
+		},
+		DialTLSContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := dialer.DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+
+			cfg := tlsConfig.Clone()
+			if cfg.ServerName == "" {
+				cfg.ServerName, _, _ = net.SplitHostPort(addr)
+			}
+			tlsConn := tls.Client(conn, cfg)
+			if err = tlsConn.HandshakeContext(ctx); err != nil {
+				_ = conn.Close()
+				return nil, err
+			}
+			return &headerConn{Conn: tlsConn}, nil
+		},
+	}
+}
+
+// rawAttributes returns object attributes from X-Attribute-* headers of the
+// raw header block keeping the original case of their names.
+func rawAttributes(header []byte) map[string]string {
+	attrs := make(map[string]string)
+
+	// the first line is a status line
+	for _, line := range bytes.Split(header, []byte("\r\n"))[1:] {
+		name, value, ok := strings.Cut(string(line), ":")
+		if !ok || len(name) <= len(attributeHeaderPrefix) ||
+			!strings.EqualFold(name[:len(attributeHeaderPrefix)], attributeHeaderPrefix) {
+			continue
+		}
+		name = name[len(attributeHeaderPrefix):]
+		if _, ok = attrs[name]; !ok {
+			attrs[name] = strings.TrimSpace(value)
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,115 @@
+package http
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/metrics"
+)
+
+// RootModule is the global module object type. It is instantiated once per test
+// run and will be used to create k6/x/neofs/http module instances for each VU.
+type RootModule struct{}
+
+// HTTP represents an instance of the module for every VU.
+type HTTP struct {
+	vu modules.VU
+}
+
+// Ensure the interfaces are implemented correctly.
+var (
+	_ modules.Instance = &HTTP{}
+	_ modules.Module   = &RootModule{}
+
+	objUploadTotal, objUploadFails, objUploadDuration       *metrics.Metric
+	objDownloadTotal, objDownloadFails, objDownloadDuration *metrics.Metric
+	objHeadTotal, objHeadFails, objHeadDuration             *metrics.Metric
+
+	objUploadStreamTotal, objUploadStreamFails, objUploadStreamDuration *metrics.Metric
+	objUploadStreamTTFB, objUploadStreamThroughput                      *metrics.Metric
+)
+
+func init() {
+	modules.Register("k6/x/neofs/http", new(RootModule))
+}
+
+// NewModuleInstance implements the modules.Module interface and returns
+// a new instance for each VU.
+func (r *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	mi := &HTTP{vu: vu}
+	return mi
+}
+
+// Exports implements the modules.Instance interface and returns the exports
+// of the JS module.
+func (h *HTTP) Exports() modules.Exports {
+	return modules.Exports{Default: h}
+}
+
+func (h *HTTP) Connect(endpoint string, params map[string]string) (*Client, error) {
+	var err error
+
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+
+	var noVerifySSL bool
+	if noVerifySSLStr, ok := params["no_verify_ssl"]; ok {
+		if noVerifySSL, err = strconv.ParseBool(noVerifySSLStr); err != nil {
+			return nil, fmt.Errorf("invalid value for 'no_verify_ssl': '%s'", noVerifySSLStr)
+		}
+	}
+
+	var timeout time.Duration
+	if timeoutStr, ok := params["timeout"]; ok {
+		if timeout, err = time.ParseDuration(timeoutStr); err != nil {
+			return nil, fmt.Errorf("invalid value for 'timeout': '%s'", timeoutStr)
+		}
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: noVerifySSL,
+	}
+	cli := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: tlsConfig,
+		},
+		Timeout: timeout,
+	}
+	headCli := &http.Client{
+		Transport: newHeadTransport(tlsConfig),
+		Timeout:   timeout,
+	}
+
+	// register metrics
+	registry := metrics.NewRegistry()
+	objUploadTotal, _ = registry.NewMetric("neofs_http_upload_total", metrics.Counter)
+	objUploadFails, _ = registry.NewMetric("neofs_http_upload_fails", metrics.Counter)
+	objUploadDuration, _ = registry.NewMetric("neofs_http_upload_duration", metrics.Trend, metrics.Time)
+
+	objDownloadTotal, _ = registry.NewMetric("neofs_http_download_total", metrics.Counter)
+	objDownloadFails, _ = registry.NewMetric("neofs_http_download_fails", metrics.Counter)
+	objDownloadDuration, _ = registry.NewMetric("neofs_http_download_duration", metrics.Trend, metrics.Time)
+
+	objHeadTotal, _ = registry.NewMetric("neofs_http_head_total", metrics.Counter)
+	objHeadFails, _ = registry.NewMetric("neofs_http_head_fails", metrics.Counter)
+	objHeadDuration, _ = registry.NewMetric("neofs_http_head_duration", metrics.Trend, metrics.Time)
+
+	objUploadStreamTotal, _ = registry.NewMetric("neofs_http_upload_stream_total", metrics.Counter)
+	objUploadStreamFails, _ = registry.NewMetric("neofs_http_upload_stream_fails", metrics.Counter)
+	objUploadStreamDuration, _ = registry.NewMetric("neofs_http_upload_stream_duration", metrics.Trend, metrics.Time)
+	objUploadStreamTTFB, _ = registry.NewMetric("neofs_http_upload_stream_ttfb", metrics.Trend, metrics.Time)
+	objUploadStreamThroughput, _ = registry.NewMetric("neofs_http_upload_stream_throughput", metrics.Trend)
+
+	return &Client{
+		vu:       h.vu,
+		cli:      cli,
+		headCli:  headCli,
+		endpoint: strings.TrimRight(endpoint, "/"),
+	}, nil
+}
@@ -0,0 +1,136 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"time"
+
+	"github.com/nspcc-dev/xk6-neofs/internal/datagen"
+	"github.com/nspcc-dev/xk6-neofs/internal/stats"
+	"go.k6.io/k6/metrics"
+)
+
+// UploadStreamResponse is a result of UploadStream, Hash is a hex-encoded
+// SHA-256 of the uploaded payload.
+type UploadStreamResponse struct {
+	Success  bool
+	ObjectID string
+	Hash     string
+	Error    string
+}
+
+// streamResult is a result of writing streamed payload into request body.
+type streamResult struct {
+	written int64
+	err     error
+}
+
+// UploadStream uploads object of the specified size with random payload.
+// Payload is generated on the fly and sent with chunked transfer encoding in
+// multipart form, so that objects of any size can be uploaded without
+// keeping them in memory. Time to the first response byte is reported in
+// neofs_http_upload_stream_ttfb and upload speed (bytes per second) in
+// neofs_http_upload_stream_throughput metrics.
+func (c *Client) UploadStream(containerID string, size int64, attributes map[string]string) UploadStreamResponse {
+	if size < 0 {
+		return UploadStreamResponse{Success: false, Error: "size should not be negative"}
+	}
+
+	hasher := sha256.New()
+	payload := io.TeeReader(datagen.NewStreamReader(size), hasher)
+
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	done := make(chan streamResult, 1)
+	go func() {
+		var res streamResult
+		part, err := mw.CreateFormFile("file", "stream.data")
+		if err == nil {
+			res.written, err = io.Copy(part, payload)
+		}
+		if err == nil {
+			err = mw.Close()
+		}
+		res.err = err
+		_ = pw.CloseWithError(err)
+		done <- res
+	}()
+	// Unblock the writer if request fails before the whole body is read.
+	defer pr.Close()
+
+	stats.Report(c.vu, objUploadStreamTotal, 1)
+	start := time.Now()
+
+	var ttfb time.Duration
+	trace := &httptrace.ClientTrace{
+		GotFirstResponseByte: func() {
+			ttfb = time.Since(start)
+		},
+	}
+
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(c.vu.Context(), trace), http.MethodPost,
+		c.endpoint+"/upload/"+url.PathEscape(containerID), pr)
+	if err != nil {
+		stats.Report(c.vu, objUploadStreamFails, 1)
+		return UploadStreamResponse{Success: false, Error: err.Error()}
+	}
+	req.TransferEncoding = []string{"chunked"}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	for k, v := range attributes {
+		req.Header.Set(attributeHeaderPrefix+k, v)
+	}
+
+	resp, err := c.cli.Do(req)
+	if err != nil {
+		stats.Report(c.vu, objUploadStreamFails, 1)
+		return UploadStreamResponse{Success: false, Error: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	if err = checkStatus(resp); err != nil {
+		stats.Report(c.vu, objUploadStreamFails, 1)
+		return UploadStreamResponse{Success: false, Error: err.Error()}
+	}
+
+	var res struct {
+		ObjectID string `json:"object_id"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		stats.Report(c.vu, objUploadStreamFails, 1)
+		return UploadStreamResponse{Success: false, Error: fmt.Sprintf("decode response: %s", err)}
+	}
+
+	// Gateway should respond after reading the whole body, otherwise the
+	// writer fails on closed pipe and the hash is calculated for a part of
+	// the payload only.
+	_ = pr.Close()
+	stream := <-done
+	if stream.err == nil && stream.written != size {
+		stream.err = fmt.Errorf("%d of %d payload bytes written", stream.written, size)
+	}
+	if stream.err != nil {
+		stats.Report(c.vu, objUploadStreamFails, 1)
+		return UploadStreamResponse{Success: false, Error: fmt.Sprintf("write payload: %s", stream.err)}
+	}
+
+	duration := time.Since(start)
+	stats.ReportDataSent(c.vu, float64(size))
+	stats.Report(c.vu, objUploadStreamDuration, metrics.D(duration))
+	stats.Report(c.vu, objUploadStreamTTFB, metrics.D(ttfb))
+	if duration > 0 {
+		stats.Report(c.vu, objUploadStreamThroughput, float64(size)/duration.Seconds())
+	}
+
+	return UploadStreamResponse{
+		Success:  true,
+		ObjectID: res.ObjectID,
+		Hash:     hex.EncodeToString(hasher.Sum(nil)),
+	}
+}
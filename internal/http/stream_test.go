@@ -0,0 +1,68 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadStream(t *testing.T) {
+	const size = 3<<20 + 42
+
+	var (
+		received int64
+		hash     string
+		unblock  = make(chan struct{})
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/upload/early" {
+			// respond without reading the body, that is much bigger than
+			// socket buffers
+			_ = json.NewEncoder(w).Encode(map[string]string{"object_id": "oid"})
+			w.(http.Flusher).Flush()
+			<-unblock
+			return
+		}
+
+		mr, err := r.MultipartReader()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		part, err := mr.NextPart()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		hasher := sha256.New()
+		if received, err = io.Copy(hasher, part); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		hash = hex.EncodeToString(hasher.Sum(nil))
+		_ = json.NewEncoder(w).Encode(map[string]string{"object_id": "oid"})
+	}))
+	defer srv.Close()
+	defer close(unblock)
+
+	cli, _ := newTestClient(t, srv.URL)
+
+	resp := cli.UploadStream("cid", size, nil)
+	require.True(t, resp.Success, resp.Error)
+	require.Equal(t, "oid", resp.ObjectID)
+	require.EqualValues(t, size, received)
+	require.Equal(t, hash, resp.Hash)
+
+	resp = cli.UploadStream("early", 64<<20, nil)
+	require.False(t, resp.Success)
+	require.Empty(t, resp.Hash)
+
+	resp = cli.UploadStream("cid", -1, nil)
+	require.False(t, resp.Success)
+}
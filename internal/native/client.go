@@ -24,6 +24,7 @@ import (
 	"github.com/nspcc-dev/neofs-sdk-go/user"
 	"github.com/nspcc-dev/neofs-sdk-go/version"
 	"github.com/nspcc-dev/tzhash/tz"
+	"github.com/nspcc-dev/xk6-neofs/internal/datagen"
 	"github.com/nspcc-dev/xk6-neofs/internal/stats"
 	"go.k6.io/k6/js/modules"
 	"go.k6.io/k6/metrics"
@@ -90,13 +91,18 @@ func (c *Client) SetBufferSize(size int) {
 	}
 }
 
-func (c *Client) Put(containerID string, headers map[string]string, payload sobek.ArrayBuffer) PutResponse {
+func (c *Client) Put(containerID string, headers map[string]string, payload sobek.Value) PutResponse {
+	data, err := datagen.PayloadBytes(payload)
+	if err != nil {
+		panic(err)
+	}
+
 	cliContainerID := parseContainerID(containerID)
 
 	tok := c.tok
 	tok.ForVerb(session.VerbObjectPut)
 	tok.BindContainer(cliContainerID)
-	err := tok.Sign(c.signer)
+	err = tok.Sign(c.signer)
 	if err != nil {
 		panic(err)
 	}
@@ -114,7 +120,7 @@ func (c *Client) Put(containerID string, headers map[string]string, payload sobe
 	o.SetOwner(c.owner)
 	o.SetAttributes(attrs...)
 
-	resp, err := put(c.vu, c.bufsize, c.cli, &tok, c.signer, &o, payload.Bytes())
+	resp, err := put(c.vu, c.bufsize, c.cli, &tok, c.signer, &o, data)
 	if err != nil {
 		return PutResponse{Success: false, Error: err.Error()}
 	}
@@ -240,7 +246,7 @@ func (c *Client) VerifyHash(containerID, objectID, expectedHash string) VerifyHa
 	}
 	actualHash := hex.EncodeToString(hasher.Sum(nil))
 	if actualHash != expectedHash {
-		return VerifyHashResponse{Success: true, Error: "hash mismatch"}
+		return VerifyHashResponse{Success: false, Error: "hash mismatch"}
 	}
 
 	return VerifyHashResponse{Success: true}
@@ -380,12 +386,15 @@ func (c *Client) Search(cnrString string, filtersJS []Filter) (int, error) {
 	return objsNum, nil
 }
 
-func (c *Client) Onsite(containerID string, payload sobek.ArrayBuffer) PreparedObject {
+func (c *Client) Onsite(containerID string, payload sobek.Value) PreparedObject {
+	data, err := datagen.PayloadBytes(payload)
+	if err != nil {
+		panic(err)
+	}
 	maxObjectSize, epoch, hhDisabled, err := parseNetworkInfo(c.vu.Context(), c.cli)
 	if err != nil {
 		panic(err)
 	}
-	data := payload.Bytes()
 	ln := len(data)
 	if ln > int(maxObjectSize) {
 		// not sure if load test needs object transformation
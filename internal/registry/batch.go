@@ -0,0 +1,165 @@
+package registry
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+var errRegistryClosed = errors.New("registry is closed")
+
+type (
+	// batchWriter commits queued writes in a single transaction when batch
+	// size is reached or batch interval elapsed, whichever comes first.
+	batchWriter struct {
+		db       *bbolt.DB
+		size     int
+		interval time.Duration
+
+		// mu protects ops from sending after close.
+		mu      sync.RWMutex
+		closed  bool
+		ops     chan func(*bbolt.Tx) error
+		flushes chan chan struct{}
+		done    chan struct{}
+
+		// commits receives results of commits for metrics, results are
+		// dropped if nobody reads them.
+		commits chan commitResult
+	}
+
+	commitResult struct {
+		duration time.Duration
+		errors   []error
+	}
+)
+
+// commitResultsBuffer is a number of commit results kept until they are
+// reported.
+const commitResultsBuffer = 1024
+
+func newBatchWriter(db *bbolt.DB, size int, interval time.Duration) *batchWriter {
+	w := &batchWriter{
+		db:       db,
+		size:     size,
+		interval: interval,
+		ops:      make(chan func(*bbolt.Tx) error, size),
+		flushes:  make(chan chan struct{}),
+		done:     make(chan struct{}),
+		commits:  make(chan commitResult, commitResultsBuffer),
+	}
+	go w.writeLoop()
+	return w
+}
+
+// write queues the write, it blocks if the queue is full.
+func (w *batchWriter) write(op func(*bbolt.Tx) error) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return errRegistryClosed
+	}
+	w.ops <- op
+	return nil
+}
+
+// queueDepth returns number of queued writes.
+func (w *batchWriter) queueDepth() int {
+	return len(w.ops)
+}
+
+// flush waits until writes queued before the call are committed.
+func (w *batchWriter) flush() {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return
+	}
+	done := make(chan struct{})
+	w.flushes <- done
+	<-done
+}
+
+// close commits queued writes and stops the writer.
+func (w *batchWriter) close() {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return
+	}
+	w.closed = true
+	close(w.ops)
+	w.mu.Unlock()
+	<-w.done
+}
+
+func (w *batchWriter) writeLoop() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	batch := make([]func(*bbolt.Tx) error, 0, w.size)
+	for {
+		select {
+		case op, ok := <-w.ops:
+			if !ok {
+				w.commit(batch)
+				return
+			}
+			batch = append(batch, op)
+			if len(batch) < w.size {
+				continue
+			}
+		case done := <-w.flushes:
+			// Take everything queued before the flush request.
+			for range len(w.ops) {
+				batch = append(batch, <-w.ops)
+			}
+			w.commit(batch)
+			batch = batch[:0]
+			close(done)
+			continue
+		case <-ticker.C:
+		}
+		w.commit(batch)
+		batch = batch[:0]
+	}
+}
+
+// commit runs all writes of the batch in a single transaction. If any write
+// fails, the transaction is rolled back and writes are run again one per
+// transaction, so that every write is committed completely or not at all.
+// Failed writes are returned in commit result, but they don't prevent other
+// writes from being committed.
+func (w *batchWriter) commit(batch []func(*bbolt.Tx) error) {
+	if len(batch) == 0 {
+		return
+	}
+
+	var res commitResult
+	start := time.Now()
+	err := w.db.Update(func(tx *bbolt.Tx) error {
+		for _, op := range batch {
+			if err := op(tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		for _, op := range batch {
+			if err := w.db.Update(op); err != nil {
+				res.errors = append(res.errors, err)
+			}
+		}
+	}
+	res.duration = time.Since(start)
+
+	select {
+	case w.commits <- res:
+	default:
+	}
+}
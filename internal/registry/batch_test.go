@@ -0,0 +1,124 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestBatchWriter(t *testing.T) {
+	t.Run("flush", func(t *testing.T) {
+		r := newTestRegistry(t)
+		r.batch = newBatchWriter(r.boltDB, 100, time.Hour)
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Go(func() {
+				for range 25 {
+					require.NoError(t, r.AddObject("c", "o", "", "", "h", 0))
+				}
+			})
+		}
+		wg.Wait()
+		r.Flush()
+		require.Equal(t, 250, countObjects(t, r, ObjFilter{Status: statusCreated}))
+
+		require.NoError(t, r.SetObjectStatus(1, statusVerified))
+		require.NoError(t, r.SetObjectStatus(2, statusCreated)) // invalid, counted as error
+		r.Flush()
+		require.Equal(t, 1, countObjects(t, r, ObjFilter{Status: statusVerified}))
+
+		var errs int
+		for len(r.batch.commits) > 0 {
+			errs += len((<-r.batch.commits).errors)
+		}
+		require.Equal(t, 1, errs)
+	})
+
+	t.Run("failed write", func(t *testing.T) {
+		r := newTestRegistry(t)
+		r.batch = newBatchWriter(r.boltDB, 100, time.Hour)
+
+		require.NoError(t, r.AddObject("c", "o1", "", "", "h", 0))
+		// Write that fails after the object is stored, it must be rolled
+		// back completely.
+		require.NoError(t, r.update(func(tx *bbolt.Tx) error {
+			b, err := tx.CreateBucketIfNotExists([]byte(bucketName))
+			if err != nil {
+				return err
+			}
+			obj := ObjectInfo{ID: 100, CID: "c", Status: statusCreated}
+			if err := b.Put(encodeID(obj.ID), encodeObject(&obj)); err != nil {
+				return err
+			}
+			return errors.New("index failure")
+		}))
+		require.NoError(t, r.AddObject("c", "o2", "", "", "h", 0))
+		require.NoError(t, r.SetObjectStatus(1, statusVerified))
+		r.Flush()
+
+		require.Equal(t, []uint64{1, 2}, selectIDs(t, r, ObjFilter{}))
+		require.Equal(t, 1, countObjects(t, r, ObjFilter{Status: statusCreated}))
+		require.Equal(t, 1, countObjects(t, r, ObjFilter{Status: statusVerified}))
+		require.Equal(t, 2, countObjects(t, r, ObjFilter{Container: "c"}))
+		obj, err := r.GetObject(100)
+		require.NoError(t, err)
+		require.Nil(t, obj)
+
+		res := <-r.batch.commits
+		require.Len(t, res.errors, 1)
+		require.EqualError(t, res.errors[0], "index failure")
+	})
+
+	t.Run("interval", func(t *testing.T) {
+		r := newTestRegistry(t)
+		r.batch = newBatchWriter(r.boltDB, 100, 10*time.Millisecond)
+
+		require.NoError(t, r.AddObject("c", "o", "", "", "h", 0))
+		require.Eventually(t, func() bool {
+			return countObjects(t, r, ObjFilter{}) == 1
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("close", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "registry.bolt")
+		r := NewObjRegistry(context.Background(), path)
+		r.batch = newBatchWriter(r.boltDB, 100, time.Hour)
+		for range 10 {
+			require.NoError(t, r.AddObject("c", "o", "", "", "h", 0))
+		}
+		require.NoError(t, r.Close())
+		require.ErrorIs(t, r.AddObject("c", "o", "", "", "h", 0), errRegistryClosed)
+
+		r = NewObjRegistry(context.Background(), path)
+		defer func() { require.NoError(t, r.Close()) }()
+		require.Equal(t, 10, countObjects(t, r, ObjFilter{}))
+	})
+}
+
+func TestParseBatchParams(t *testing.T) {
+	size, interval, err := parseBatchParams(nil)
+	require.NoError(t, err)
+	require.Zero(t, size)
+	require.Equal(t, defaultBatchInterval, interval)
+
+	size, interval, err = parseBatchParams(map[string]string{"batch_size": "500", "batch_interval": "1s"})
+	require.NoError(t, err)
+	require.Equal(t, 500, size)
+	require.Equal(t, time.Second, interval)
+
+	for _, params := range []map[string]string{
+		{"batch_size": "-1"},
+		{"batch_size": "x"},
+		{"batch_interval": "0s"},
+	} {
+		_, _, err = parseBatchParams(params)
+		require.Error(t, err, params)
+	}
+}
@@ -0,0 +1,186 @@
+package registry
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+// Objects are stored in compact binary format: version byte followed by
+// fields in the order of ObjectInfo declaration. Integers and times (Unix
+// nanoseconds, 0 for zero time) are varints, strings, lists and maps (with
+// keys sorted) are prefixed with varint length. Records written by previous
+// versions are JSON objects, they are recognized by the first '{' byte and
+// rewritten in binary format on the next update.
+const (
+	encodingV1 byte = 1
+
+	jsonPrefix = '{'
+)
+
+var errShortRecord = errors.New("unexpected end of record")
+
+func encodeObject(obj *ObjectInfo) []byte {
+	buf := make([]byte, 0, 128)
+	buf = append(buf, encodingV1)
+	buf = binary.AppendUvarint(buf, obj.ID)
+	buf = appendTime(buf, obj.CreatedAt)
+	buf = appendString(buf, obj.CID)
+	buf = appendString(buf, obj.OID)
+	buf = appendString(buf, obj.S3Bucket)
+	buf = appendString(buf, obj.S3Key)
+	buf = appendString(buf, obj.Status)
+	buf = appendString(buf, obj.PayloadHash)
+	buf = appendString(buf, obj.PayloadSeed)
+	buf = binary.AppendVarint(buf, obj.PayloadSize)
+	buf = appendTime(buf, obj.StatusChangedAt)
+
+	buf = binary.AppendUvarint(buf, uint64(len(obj.History)))
+	for _, h := range obj.History {
+		buf = appendString(buf, h.Status)
+		buf = appendTime(buf, h.At)
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(obj.Attempts)))
+	for _, a := range obj.Attempts {
+		buf = appendString(buf, a.Operation)
+		buf = appendTime(buf, a.At)
+		buf = appendString(buf, a.Error)
+	}
+
+	buf = binary.AppendUvarint(buf, uint64(len(obj.PayloadParams)))
+	for _, k := range slices.Sorted(maps.Keys(obj.PayloadParams)) {
+		buf = appendString(buf, k)
+		buf = appendString(buf, obj.PayloadParams[k])
+	}
+	return buf
+}
+
+func decodeObject(data []byte, obj *ObjectInfo) error {
+	if len(data) == 0 {
+		return errShortRecord
+	}
+	switch data[0] {
+	case jsonPrefix:
+		return json.Unmarshal(data, obj)
+	case encodingV1:
+	default:
+		return fmt.Errorf("unknown record version %d", data[0])
+	}
+
+	d := decoder{data: data[1:]}
+	obj.ID = d.uvarint()
+	obj.CreatedAt = d.time()
+	obj.CID = d.string()
+	obj.OID = d.string()
+	obj.S3Bucket = d.string()
+	obj.S3Key = d.string()
+	obj.Status = d.string()
+	obj.PayloadHash = d.string()
+	obj.PayloadSeed = d.string()
+	obj.PayloadSize = d.varint()
+	obj.StatusChangedAt = d.time()
+
+	obj.History = nil
+	if n := d.length(); n > 0 {
+		obj.History = make([]StatusChange, n)
+		for i := range obj.History {
+			obj.History[i] = StatusChange{Status: d.string(), At: d.time()}
+		}
+	}
+	obj.Attempts = nil
+	if n := d.length(); n > 0 {
+		obj.Attempts = make([]Attempt, n)
+		for i := range obj.Attempts {
+			obj.Attempts[i] = Attempt{Operation: d.string(), At: d.time(), Error: d.string()}
+		}
+	}
+	obj.PayloadParams = nil
+	if n := d.length(); n > 0 {
+		obj.PayloadParams = make(map[string]string, n)
+		for range n {
+			k := d.string()
+			obj.PayloadParams[k] = d.string()
+		}
+	}
+	return d.err
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+func appendTime(buf []byte, t time.Time) []byte {
+	if t.IsZero() {
+		return binary.AppendVarint(buf, 0)
+	}
+	return binary.AppendVarint(buf, t.UnixNano())
+}
+
+// decoder reads fields of binary record, the first error is kept and all
+// subsequent reads return zero values.
+type decoder struct {
+	data []byte
+	err  error
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.err = errShortRecord
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *decoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(d.data)
+	if n <= 0 {
+		d.err = errShortRecord
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+// length reads length of the list or string checking that it fits into
+// the rest of the record, since every element takes at least a byte.
+func (d *decoder) length() int {
+	l := d.uvarint()
+	if l > uint64(len(d.data)) {
+		if d.err == nil {
+			d.err = errShortRecord
+		}
+		return 0
+	}
+	return int(l)
+}
+
+func (d *decoder) string() string {
+	l := d.length()
+	if d.err != nil {
+		return ""
+	}
+	s := string(d.data[:l])
+	d.data = d.data[l:]
+	return s
+}
+
+func (d *decoder) time() time.Time {
+	ns := d.varint()
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns).UTC()
+}
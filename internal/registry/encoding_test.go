@@ -0,0 +1,213 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func testObject() ObjectInfo {
+	now := time.Now().UTC()
+	return ObjectInfo{
+		ID:              42,
+		CreatedAt:       now.Add(-time.Hour),
+		CID:             "BzQw5HH3feoxFDD5tCT87Y1726qzgLfxEE7wgtoRzB3R",
+		OID:             "AWCWCqBThYBfmzMVmPR2GXCGYD5iWPngwP4xW1MWkEbz",
+		Status:          statusVerified,
+		PayloadHash:     "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		PayloadSeed:     "18446744073709551615",
+		PayloadSize:     1 << 20,
+		StatusChangedAt: now,
+		History: []StatusChange{
+			{Status: statusCreated, At: now.Add(-time.Hour)},
+			{Status: statusVerified, At: now},
+		},
+		Attempts: []Attempt{
+			{Operation: "verify", At: now.Add(-time.Minute), Error: "timeout"},
+			{Operation: "verify", At: now},
+		},
+		PayloadParams: map[string]string{"mode": "compressible", "compression_ratio": "3"},
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	t.Run("binary", func(t *testing.T) {
+		for _, obj := range []ObjectInfo{testObject(), {}, {S3Bucket: "b", S3Key: "k", PayloadSize: -1}} {
+			var res ObjectInfo
+			require.NoError(t, decodeObject(encodeObject(&obj), &res))
+			require.Equal(t, obj, res)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		obj := testObject()
+		data, err := json.Marshal(obj)
+		require.NoError(t, err)
+
+		var res ObjectInfo
+		require.NoError(t, decodeObject(data, &res))
+		require.Equal(t, obj, res)
+	})
+
+	t.Run("smaller than json", func(t *testing.T) {
+		obj := testObject()
+		data, err := json.Marshal(obj)
+		require.NoError(t, err)
+		require.Less(t, len(encodeObject(&obj))*2, len(data))
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		obj := testObject()
+		data := encodeObject(&obj)
+		for i := range len(data) {
+			require.Error(t, decodeObject(data[:i], new(ObjectInfo)), i)
+		}
+		require.Error(t, decodeObject([]byte{0xff}, new(ObjectInfo)))
+	})
+
+	t.Run("legacy record is rewritten", func(t *testing.T) {
+		r := newTestRegistry(t)
+		obj := ObjectInfo{ID: 1, CID: "c", OID: "o", Status: statusCreated, CreatedAt: time.Now().UTC()}
+		require.NoError(t, r.boltDB.Update(func(tx *bbolt.Tx) error {
+			b, err := tx.CreateBucketIfNotExists([]byte(bucketName))
+			require.NoError(t, err)
+			data, err := json.Marshal(obj)
+			require.NoError(t, err)
+			return b.Put(encodeID(1), data)
+		}))
+
+		require.NoError(t, r.SetObjectStatus(1, statusVerified))
+		require.NoError(t, r.boltDB.View(func(tx *bbolt.Tx) error {
+			require.Equal(t, encodingV1, tx.Bucket([]byte(bucketName)).Get(encodeID(1))[0])
+			return nil
+		}))
+		res, err := r.GetObject(1)
+		require.NoError(t, err)
+		require.Equal(t, statusVerified, res.Status)
+		require.Equal(t, obj.CreatedAt, res.CreatedAt)
+	})
+}
+
+// addObjectJSON stores object in JSON format as previous versions did.
+func addObjectJSON(r *ObjRegistry, obj ObjectInfo) error {
+	return r.boltDB.Update(func(tx *bbolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucketName))
+		if err != nil {
+			return err
+		}
+		id, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		obj.ID = id
+		obj.CreatedAt = time.Now().UTC()
+		obj.Status = statusCreated
+		obj.StatusChangedAt = obj.CreatedAt
+		obj.History = []StatusChange{{Status: statusCreated, At: obj.CreatedAt}}
+		data, err := json.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		if err := b.Put(encodeID(id), data); err != nil {
+			return err
+		}
+		return indexObject(tx, &obj)
+	})
+}
+
+func BenchmarkEncoding(b *testing.B) {
+	obj := testObject()
+	jsonData, _ := json.Marshal(obj)
+	binData := encodeObject(&obj)
+
+	b.Run("encode/json", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = json.Marshal(obj)
+		}
+	})
+	b.Run("encode/binary", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_ = encodeObject(&obj)
+		}
+	})
+	b.Run("decode/json", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			var res ObjectInfo
+			_ = decodeObject(jsonData, &res)
+		}
+	})
+	b.Run("decode/binary", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			var res ObjectInfo
+			_ = decodeObject(binData, &res)
+		}
+	})
+}
+
+func BenchmarkAddObject(b *testing.B) {
+	obj := testObject()
+	add := map[string]func(r *ObjRegistry) error{
+		"json": func(r *ObjRegistry) error {
+			return addObjectJSON(r, ObjectInfo{CID: obj.CID, OID: obj.OID, PayloadHash: obj.PayloadHash})
+		},
+		"binary": func(r *ObjRegistry) error {
+			return r.AddObject(obj.CID, obj.OID, "", "", obj.PayloadHash, 0)
+		},
+	}
+	for _, format := range []string{"json", "binary"} {
+		b.Run(format, func(b *testing.B) {
+			r := newTestRegistry(b)
+			b.ReportAllocs()
+			for b.Loop() {
+				if err := add[format](r); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkSelectObjects(b *testing.B) {
+	const count = 10000
+	obj := testObject()
+
+	for _, format := range []string{"json", "binary"} {
+		b.Run(fmt.Sprintf("%s/%d", format, count), func(b *testing.B) {
+			r := newTestRegistry(b)
+			for range count {
+				var err error
+				if format == "json" {
+					err = addObjectJSON(r, ObjectInfo{CID: obj.CID, OID: obj.OID, PayloadHash: obj.PayloadHash})
+				} else {
+					err = r.AddObject(obj.CID, obj.OID, "", "", obj.PayloadHash, 0)
+				}
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			filter := &ObjFilter{Status: statusCreated}
+			b.ReportAllocs()
+			for b.Loop() {
+				var n int
+				_ = r.boltDB.View(func(tx *bbolt.Tx) error {
+					return scanObjects(tx, filter, 0, func(*ObjectInfo) bool {
+						n++
+						return true
+					})
+				})
+				if n != count {
+					b.Fatalf("selected %d objects", n)
+				}
+			}
+		})
+	}
+}
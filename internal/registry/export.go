@@ -0,0 +1,315 @@
+package registry
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// Formats of exported and imported registry contents.
+const (
+	// FormatJSONL is JSON Lines, one object per line with all its fields.
+	FormatJSONL = "jsonl"
+	// FormatCSV is CSV with header, status history and attempts are omitted.
+	FormatCSV = "csv"
+	// FormatPreset is JSON produced by preset_grpc.py and preset_s3.py
+	// scripts, it can only be imported.
+	FormatPreset = "preset"
+)
+
+// importBatchSize is the number of objects imported in a single transaction.
+const importBatchSize = 1000
+
+type (
+	// objectRecord is an exported object, field names are the same as in JS.
+	objectRecord struct {
+		ID              uint64            `json:"id"`
+		CreatedAt       time.Time         `json:"created_at"`
+		CID             string            `json:"c_id,omitempty"`
+		OID             string            `json:"o_id,omitempty"`
+		S3Bucket        string            `json:"s3_bucket,omitempty"`
+		S3Key           string            `json:"s3_key,omitempty"`
+		Status          string            `json:"status"`
+		PayloadHash     string            `json:"payload_hash,omitempty"`
+		PayloadSeed     string            `json:"payload_seed,omitempty"`
+		PayloadSize     int64             `json:"payload_size,omitempty"`
+		PayloadParams   map[string]string `json:"payload_params,omitempty"`
+		StatusChangedAt time.Time         `json:"status_changed_at"`
+		History         []statusRecord    `json:"history,omitempty"`
+		Attempts        []attemptRecord   `json:"attempts,omitempty"`
+	}
+
+	statusRecord struct {
+		Status string    `json:"status"`
+		At     time.Time `json:"at"`
+	}
+
+	attemptRecord struct {
+		Operation string    `json:"operation"`
+		At        time.Time `json:"at"`
+		Error     string    `json:"error,omitempty"`
+	}
+
+	presetFile struct {
+		Objects []struct {
+			Container string `json:"container"`
+			Bucket    string `json:"bucket"`
+			Object    string `json:"object"`
+		} `json:"objects"`
+	}
+)
+
+var csvHeader = []string{
+	"id", "created_at", "c_id", "o_id", "s3_bucket", "s3_key", "status",
+	"payload_hash", "payload_seed", "payload_size", "status_changed_at",
+	"payload_params",
+}
+
+// ExportObjects writes objects matching the filter to the file in the
+// specified format (jsonl or csv) and returns the number of exported objects.
+// The filter has the same keys as selector filter.
+func (o *ObjRegistry) ExportObjects(path, format string, filter map[string]string) (int, error) {
+	objFilter, err := parseFilter(filter)
+	if err != nil {
+		return 0, err
+	}
+	if format != FormatJSONL && format != FormatCSV {
+		return 0, fmt.Errorf("unknown export format: '%s'", format)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	n, err := o.Export(f, format, objFilter)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return n, err
+}
+
+// ImportObjects reads objects from the file in the specified format (jsonl or
+// preset) and adds them to the registry, returns the number of imported
+// objects.
+func (o *ObjRegistry) ImportObjects(path, format string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return o.Import(f, format)
+}
+
+// Export writes objects matching the filter in the specified format.
+func (o *ObjRegistry) Export(w io.Writer, format string, filter *ObjFilter) (int, error) {
+	var write func(*ObjectInfo) error
+	bw := bufio.NewWriter(w)
+
+	switch format {
+	case FormatJSONL:
+		enc := json.NewEncoder(bw)
+		write = func(obj *ObjectInfo) error {
+			return enc.Encode(newObjectRecord(obj))
+		}
+	case FormatCSV:
+		cw := csv.NewWriter(bw)
+		if err := cw.Write(csvHeader); err != nil {
+			return 0, err
+		}
+		write = func(obj *ObjectInfo) error {
+			// parameters are exported as JSON object in a single column
+			var params []byte
+			if len(obj.PayloadParams) > 0 {
+				params, _ = json.Marshal(obj.PayloadParams)
+			}
+			err := cw.Write([]string{
+				strconv.FormatUint(obj.ID, 10),
+				formatTime(obj.CreatedAt),
+				obj.CID,
+				obj.OID,
+				obj.S3Bucket,
+				obj.S3Key,
+				obj.Status,
+				obj.PayloadHash,
+				obj.PayloadSeed,
+				strconv.FormatInt(obj.PayloadSize, 10),
+				formatTime(obj.StatusChangedAt),
+				string(params),
+			})
+			if err != nil {
+				return err
+			}
+			cw.Flush()
+			return cw.Error()
+		}
+	default:
+		return 0, fmt.Errorf("unknown export format: '%s'", format)
+	}
+
+	o.Flush()
+	var n int
+	err := o.boltDB.View(func(tx *bbolt.Tx) error {
+		var writeErr error
+		err := scanObjects(tx, filter, 0, func(obj *ObjectInfo) bool {
+			if writeErr = write(obj); writeErr != nil {
+				return false
+			}
+			n++
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		return writeErr
+	})
+	if err != nil {
+		return n, err
+	}
+	return n, bw.Flush()
+}
+
+// Import adds objects read in the specified format to the registry. Objects
+// get new IDs, other fields of JSON Lines records are kept, objects from
+// preset are added in created status without payload hash and seed, so
+// verification skips them. All records are read and checked before anything
+// is written, then objects are committed in transactions of 1000 objects. If
+// a commit fails, objects committed before it stay in the registry, their
+// number is returned together with the error.
+func (o *ObjRegistry) Import(r io.Reader, format string) (int, error) {
+	var objects []*ObjectInfo
+
+	switch format {
+	case FormatJSONL:
+		dec := json.NewDecoder(r)
+		for line := 1; ; line++ {
+			var rec objectRecord
+			err := dec.Decode(&rec)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return 0, fmt.Errorf("record %d: %w", line, err)
+			}
+			obj := rec.objectInfo()
+			if _, ok := statusTransitions[obj.Status]; !ok {
+				return 0, fmt.Errorf("record %d: unknown status: '%s'", line, obj.Status)
+			}
+			objects = append(objects, obj)
+		}
+	case FormatPreset:
+		var preset presetFile
+		if err := json.NewDecoder(r).Decode(&preset); err != nil {
+			return 0, fmt.Errorf("decode preset: %w", err)
+		}
+		now := time.Now().UTC()
+		for _, p := range preset.Objects {
+			obj := &ObjectInfo{CreatedAt: now, Status: statusCreated, StatusChangedAt: now}
+			if p.Bucket != "" {
+				obj.S3Bucket, obj.S3Key = p.Bucket, p.Object
+			} else {
+				obj.CID, obj.OID = p.Container, p.Object
+			}
+			obj.History = []StatusChange{{Status: statusCreated, At: now}}
+			objects = append(objects, obj)
+		}
+	default:
+		return 0, fmt.Errorf("unknown import format: '%s'", format)
+	}
+
+	o.Flush()
+	for i := 0; i < len(objects); i += importBatchSize {
+		batch := objects[i:min(i+importBatchSize, len(objects))]
+		err := o.boltDB.Update(func(tx *bbolt.Tx) error {
+			b, err := tx.CreateBucketIfNotExists([]byte(bucketName))
+			if err != nil {
+				return err
+			}
+			for _, obj := range batch {
+				if obj.ID, err = b.NextSequence(); err != nil {
+					return err
+				}
+				if err = b.Put(encodeID(obj.ID), encodeObject(obj)); err != nil {
+					return err
+				}
+				if err = indexObject(tx, obj); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return i, fmt.Errorf("import failed after %d objects: %w", i, err)
+		}
+	}
+	return len(objects), nil
+}
+
+func newObjectRecord(obj *ObjectInfo) objectRecord {
+	rec := objectRecord{
+		ID:              obj.ID,
+		CreatedAt:       obj.CreatedAt,
+		CID:             obj.CID,
+		OID:             obj.OID,
+		S3Bucket:        obj.S3Bucket,
+		S3Key:           obj.S3Key,
+		Status:          obj.Status,
+		PayloadHash:     obj.PayloadHash,
+		PayloadSeed:     obj.PayloadSeed,
+		PayloadSize:     obj.PayloadSize,
+		PayloadParams:   obj.PayloadParams,
+		StatusChangedAt: obj.StatusChangedAt,
+	}
+	for _, h := range obj.History {
+		rec.History = append(rec.History, statusRecord(h))
+	}
+	for _, a := range obj.Attempts {
+		rec.Attempts = append(rec.Attempts, attemptRecord(a))
+	}
+	return rec
+}
+
+func (rec objectRecord) objectInfo() *ObjectInfo {
+	obj := &ObjectInfo{
+		CreatedAt:       rec.CreatedAt.UTC(),
+		CID:             rec.CID,
+		OID:             rec.OID,
+		S3Bucket:        rec.S3Bucket,
+		S3Key:           rec.S3Key,
+		Status:          rec.Status,
+		PayloadHash:     rec.PayloadHash,
+		PayloadSeed:     rec.PayloadSeed,
+		PayloadSize:     rec.PayloadSize,
+		PayloadParams:   rec.PayloadParams,
+		StatusChangedAt: rec.StatusChangedAt.UTC(),
+	}
+	if obj.CreatedAt.IsZero() {
+		obj.CreatedAt = time.Now().UTC()
+	}
+	if obj.Status == "" {
+		obj.Status = statusCreated
+	}
+	if obj.StatusChangedAt.IsZero() {
+		obj.StatusChangedAt = obj.CreatedAt
+	}
+	for _, h := range rec.History {
+		obj.History = append(obj.History, StatusChange{Status: h.Status, At: h.At.UTC()})
+	}
+	for _, a := range rec.Attempts {
+		obj.Attempts = append(obj.Attempts, Attempt{Operation: a.Operation, At: a.At.UTC(), Error: a.Error})
+	}
+	return obj
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,135 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestExportImportJSONL(t *testing.T) {
+	src := newTestRegistry(t)
+	require.NoError(t, src.AddObject("c1", "o1", "", "", "h1", 0))
+	require.NoError(t, src.AddSeededObject("", "", "b1", "k1", "seed", 1024, map[string]string{"mode": "text"}))
+	require.NoError(t, src.AddObject("c2", "o2", "", "", "h2", 0))
+	require.NoError(t, src.SetObjectStatus(2, statusVerified))
+	require.NoError(t, src.AddAttempt(3, "delete", "access denied"))
+
+	var buf bytes.Buffer
+	n, err := src.Export(&buf, FormatJSONL, &ObjFilter{})
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	require.Equal(t, 3, strings.Count(buf.String(), "\n"))
+
+	dst := newTestRegistry(t)
+	require.NoError(t, dst.AddObject("c0", "o0", "", "", "h0", 0))
+	n, err = dst.Import(&buf, FormatJSONL)
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+
+	for id := uint64(1); id <= 3; id++ {
+		expected, err := src.GetObject(id)
+		require.NoError(t, err)
+		actual, err := dst.GetObject(id + 1)
+		require.NoError(t, err)
+		expected.ID = actual.ID
+		require.Equal(t, expected, actual)
+	}
+	require.Equal(t, 1, countObjects(t, dst, ObjFilter{Status: statusVerified}))
+	require.Equal(t, 1, countObjects(t, dst, ObjFilter{Container: "c2"}))
+}
+
+func TestExportFilterCSV(t *testing.T) {
+	r := newTestRegistry(t)
+	require.NoError(t, r.AddObject("c1", "o1", "", "", "h1", 0))
+	require.NoError(t, r.AddObject("c2", "o2", "", "", "h2", 0))
+	require.NoError(t, r.AddObject("c1", "o3", "", "", "h3", 0))
+
+	path := filepath.Join(t.TempDir(), "objects.csv")
+	n, err := r.ExportObjects(path, FormatCSV, map[string]string{"container": "c1"})
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	require.Equal(t, csvHeader, records[0])
+	require.Equal(t, []string{"1", "c1", "o1", "created", "h1"},
+		[]string{records[1][0], records[1][2], records[1][3], records[1][6], records[1][7]})
+	require.Equal(t, "3", records[2][0])
+}
+
+func TestImportPreset(t *testing.T) {
+	const preset = `{
+		"containers": ["c1"],
+		"buckets": ["b1"],
+		"objects": [
+			{"container": "c1", "object": "o1"},
+			{"container": "c1", "object": "o2"},
+			{"bucket": "b1", "object": "k1"}
+		],
+		"obj_size": "1 Kb"
+	}`
+
+	r := newTestRegistry(t)
+	n, err := r.Import(strings.NewReader(preset), FormatPreset)
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+
+	obj, err := r.GetObject(3)
+	require.NoError(t, err)
+	require.Equal(t, "b1", obj.S3Bucket)
+	require.Equal(t, "k1", obj.S3Key)
+	require.Equal(t, statusCreated, obj.Status)
+	// Verification skips objects that have neither hash nor seed
+	require.Empty(t, obj.PayloadHash)
+	require.Empty(t, obj.PayloadSeed)
+	require.Equal(t, 2, countObjects(t, r, ObjFilter{Container: "c1"}))
+	require.Equal(t, 3, countObjects(t, r, ObjFilter{Status: statusCreated}))
+}
+
+func TestImportInvalid(t *testing.T) {
+	r := newTestRegistry(t)
+
+	_, err := r.Import(strings.NewReader(`{"status": "lost"}`), FormatJSONL)
+	require.Error(t, err)
+	_, err = r.Import(strings.NewReader(`{"objects": `), FormatPreset)
+	require.Error(t, err)
+	_, err = r.Import(strings.NewReader(``), "csv")
+	require.Error(t, err)
+	_, err = r.Export(&bytes.Buffer{}, "xml", &ObjFilter{})
+	require.Error(t, err)
+	require.Equal(t, 0, countObjects(t, r, ObjFilter{}))
+}
+
+func TestImportPartial(t *testing.T) {
+	r := newTestRegistry(t)
+
+	// Plain key in place of index bucket makes indexing of the object fail
+	err := r.boltDB.Update(func(tx *bbolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(locationIndexName))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(objectLocation("bad", "")), []byte{})
+	})
+	require.NoError(t, err)
+
+	var records strings.Builder
+	for range importBatchSize {
+		records.WriteString(`{"c_id": "c1", "o_id": "o1"}` + "\n")
+	}
+	records.WriteString(`{"c_id": "bad", "o_id": "o1"}` + "\n")
+
+	n, err := r.Import(strings.NewReader(records.String()), FormatJSONL)
+	require.ErrorContains(t, err, "import failed after 1000 objects")
+	require.Equal(t, importBatchSize, n)
+	require.Equal(t, importBatchSize, countObjects(t, r, ObjFilter{}))
+}
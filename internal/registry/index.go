@@ -0,0 +1,222 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strconv"
+
+	"go.etcd.io/bbolt"
+	berrors "go.etcd.io/bbolt/errors"
+)
+
+// Secondary indexes of the registry. Every index bucket contains nested bucket
+// per indexed value (status or object location) with IDs of matching objects
+// as keys, so that objects can be selected without scanning all records.
+// Number of objects in every nested bucket is kept in counters bucket along
+// with total payload size and numbers of objects created in every minute,
+// so that registry stats don't require scanning all objects.
+const (
+	statusIndexName   = "_status"
+	locationIndexName = "_location"
+	countersName      = "_count"
+	metaName          = "_meta"
+
+	payloadCounterName = "_payload"
+	createdCounterName = "_created"
+)
+
+// indexVersion is stored in meta bucket, indexes are rebuilt on open if the
+// database has a different version.
+const indexVersion = 2
+
+var indexVersionKey = []byte("index_version")
+
+// objectLocation returns location of the object used in location index:
+// S3 bucket name or container ID with a prefix, so that they never clash.
+func objectLocation(cid, s3Bucket string) string {
+	switch {
+	case s3Bucket != "":
+		return "s3/" + s3Bucket
+	case cid != "":
+		return "cid/" + cid
+	default:
+		return ""
+	}
+}
+
+// indexObject adds object to status and location indexes and updates stats
+// counters.
+func indexObject(tx *bbolt.Tx, obj *ObjectInfo) error {
+	if b := indexBucket(tx, statusIndexName, obj.Status); b != nil && b.Get(encodeID(obj.ID)) != nil {
+		return nil
+	}
+	if err := addToIndex(tx, statusIndexName, obj.Status, obj.ID); err != nil {
+		return err
+	}
+	if err := updateStatsCounters(tx, obj, 1); err != nil {
+		return err
+	}
+	if loc := objectLocation(obj.CID, obj.S3Bucket); loc != "" {
+		return addToIndex(tx, locationIndexName, loc, obj.ID)
+	}
+	return nil
+}
+
+// unindexObject removes object from status and location indexes and updates
+// stats counters.
+func unindexObject(tx *bbolt.Tx, obj *ObjectInfo) error {
+	if b := indexBucket(tx, statusIndexName, obj.Status); b == nil || b.Get(encodeID(obj.ID)) == nil {
+		return nil
+	}
+	if err := removeFromIndex(tx, statusIndexName, obj.Status, obj.ID); err != nil {
+		return err
+	}
+	if err := updateStatsCounters(tx, obj, -1); err != nil {
+		return err
+	}
+	if loc := objectLocation(obj.CID, obj.S3Bucket); loc != "" {
+		return removeFromIndex(tx, locationIndexName, loc, obj.ID)
+	}
+	return nil
+}
+
+// updateStatsCounters adds (sign is 1) or removes (sign is -1) the object to
+// total payload size and number of objects created in the same minute.
+func updateStatsCounters(tx *bbolt.Tx, obj *ObjectInfo, sign int64) error {
+	if obj.PayloadSize > 0 {
+		if err := addCounter(tx, payloadCounterName, "", sign*obj.PayloadSize); err != nil {
+			return err
+		}
+	}
+	minute := strconv.FormatInt(obj.CreatedAt.Unix()/60, 10)
+	return addCounter(tx, createdCounterName, minute, sign)
+}
+
+func addToIndex(tx *bbolt.Tx, index, value string, id uint64) error {
+	root, err := tx.CreateBucketIfNotExists([]byte(index))
+	if err != nil {
+		return err
+	}
+	b, err := root.CreateBucketIfNotExists([]byte(value))
+	if err != nil {
+		return err
+	}
+	key := encodeID(id)
+	if b.Get(key) != nil {
+		return nil
+	}
+	if err := b.Put(key, []byte{}); err != nil {
+		return err
+	}
+	return addCounter(tx, index, value, 1)
+}
+
+func removeFromIndex(tx *bbolt.Tx, index, value string, id uint64) error {
+	b := indexBucket(tx, index, value)
+	if b == nil {
+		return nil
+	}
+	key := encodeID(id)
+	if b.Get(key) == nil {
+		return nil
+	}
+	if err := b.Delete(key); err != nil {
+		return err
+	}
+	return addCounter(tx, index, value, -1)
+}
+
+// indexBucket returns bucket of the index value or nil if there are no
+// objects with such value.
+func indexBucket(tx *bbolt.Tx, index, value string) *bbolt.Bucket {
+	root := tx.Bucket([]byte(index))
+	if root == nil {
+		return nil
+	}
+	return root.Bucket([]byte(value))
+}
+
+func counterKey(index, value string) []byte {
+	return []byte(index + "/" + value)
+}
+
+func addCounter(tx *bbolt.Tx, index, value string, delta int64) error {
+	b, err := tx.CreateBucketIfNotExists([]byte(countersName))
+	if err != nil {
+		return err
+	}
+	key := counterKey(index, value)
+	n := int64(decodeCounter(b.Get(key))) + delta
+	if n <= 0 {
+		return b.Delete(key)
+	}
+	return b.Put(key, encodeID(uint64(n)))
+}
+
+// readCounter returns number of objects with the index value.
+func readCounter(tx *bbolt.Tx, index, value string) uint64 {
+	b := tx.Bucket([]byte(countersName))
+	if b == nil {
+		return 0
+	}
+	return decodeCounter(b.Get(counterKey(index, value)))
+}
+
+// readCounters returns numbers of objects for all values of the index.
+func readCounters(tx *bbolt.Tx, index string) map[string]uint64 {
+	res := make(map[string]uint64)
+	b := tx.Bucket([]byte(countersName))
+	if b == nil {
+		return res
+	}
+	prefix := []byte(index + "/")
+	c := b.Cursor()
+	for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		res[string(k[len(prefix):])] = decodeCounter(v)
+	}
+	return res
+}
+
+func decodeCounter(v []byte) uint64 {
+	if len(v) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(v)
+}
+
+// ensureIndexes rebuilds indexes if they were built by another version or
+// the database was created before indexes were introduced.
+func ensureIndexes(db *bbolt.DB) error {
+	return db.Update(func(tx *bbolt.Tx) error {
+		meta, err := tx.CreateBucketIfNotExists([]byte(metaName))
+		if err != nil {
+			return err
+		}
+		if decodeCounter(meta.Get(indexVersionKey)) == indexVersion {
+			return nil
+		}
+
+		for _, name := range []string{statusIndexName, locationIndexName, countersName} {
+			if err := tx.DeleteBucket([]byte(name)); err != nil && !errors.Is(err, berrors.ErrBucketNotFound) {
+				return err
+			}
+		}
+
+		if b := tx.Bucket([]byte(bucketName)); b != nil {
+			err = b.ForEach(func(_, objBytes []byte) error {
+				var obj ObjectInfo
+				if err := decodeObject(objBytes, &obj); err != nil {
+					// Malformed objects can't be selected anyway
+					return nil
+				}
+				return indexObject(tx, &obj)
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return meta.Put(indexVersionKey, encodeID(indexVersion))
+	})
+}
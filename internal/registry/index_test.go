@@ -0,0 +1,112 @@
+package registry
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func countObjects(t *testing.T, r *ObjRegistry, filter ObjFilter) int {
+	s := &ObjSelector{boltDB: r.boltDB, filter: &filter}
+	n, err := s.Count()
+	require.NoError(t, err)
+	return n
+}
+
+func selectIDs(t *testing.T, r *ObjRegistry, filter ObjFilter) []uint64 {
+	var ids []uint64
+	err := r.boltDB.View(func(tx *bbolt.Tx) error {
+		return scanObjects(tx, &filter, 0, func(obj *ObjectInfo) bool {
+			ids = append(ids, obj.ID)
+			return true
+		})
+	})
+	require.NoError(t, err)
+	return ids
+}
+
+func TestIndexes(t *testing.T) {
+	r := newTestRegistry(t)
+
+	require.NoError(t, r.AddObject("c1", "o1", "", "", "h", 0))
+	require.NoError(t, r.AddObject("c2", "o2", "", "", "h", 0))
+	require.NoError(t, r.AddObject("", "", "b1", "k1", "h", 0))
+	require.NoError(t, r.AddObject("c1", "o3", "", "", "h", 0))
+	require.NoError(t, r.SetObjectStatus(2, statusVerified))
+	require.NoError(t, r.SetObjectStatus(4, statusInvalid))
+
+	require.Equal(t, 4, countObjects(t, r, ObjFilter{}))
+	require.Equal(t, 2, countObjects(t, r, ObjFilter{Status: statusCreated}))
+	require.Equal(t, 1, countObjects(t, r, ObjFilter{Status: statusVerified}))
+	require.Equal(t, 0, countObjects(t, r, ObjFilter{Status: statusDeleted}))
+	require.Equal(t, 2, countObjects(t, r, ObjFilter{Container: "c1"}))
+	require.Equal(t, 1, countObjects(t, r, ObjFilter{Container: "c1", Status: statusCreated}))
+	require.Equal(t, 1, countObjects(t, r, ObjFilter{Bucket: "b1"}))
+
+	require.Equal(t, []uint64{1, 3}, selectIDs(t, r, ObjFilter{Status: statusCreated}))
+	require.Equal(t, []uint64{1, 4}, selectIDs(t, r, ObjFilter{Container: "c1"}))
+	require.Equal(t, []uint64{1, 2, 3, 4}, selectIDs(t, r, ObjFilter{}))
+	require.Empty(t, selectIDs(t, r, ObjFilter{Age: 60}))
+
+	require.NoError(t, r.DeleteObject(1))
+	require.NoError(t, r.DeleteObject(1))
+	require.Equal(t, 1, countObjects(t, r, ObjFilter{Status: statusCreated}))
+	require.Equal(t, 1, countObjects(t, r, ObjFilter{Container: "c1"}))
+	require.Equal(t, []uint64{3}, selectIDs(t, r, ObjFilter{Status: statusCreated}))
+}
+
+func TestIndexesRebuild(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registry.bolt")
+	r := NewObjRegistry(context.Background(), path)
+	require.NoError(t, r.AddObject("c1", "o1", "", "", "h", 0))
+	require.NoError(t, r.AddObject("c1", "o2", "", "", "h", 100))
+	require.NoError(t, r.SetObjectStatus(2, statusVerified))
+
+	// emulate database created before indexes
+	require.NoError(t, r.boltDB.Update(func(tx *bbolt.Tx) error {
+		for _, name := range []string{statusIndexName, locationIndexName, countersName, metaName} {
+			require.NoError(t, tx.DeleteBucket([]byte(name)))
+		}
+		return nil
+	}))
+	require.NoError(t, r.Close())
+
+	r = NewObjRegistry(context.Background(), path)
+	defer func() { require.NoError(t, r.Close()) }()
+
+	require.Equal(t, 1, countObjects(t, r, ObjFilter{Status: statusCreated}))
+	require.Equal(t, 1, countObjects(t, r, ObjFilter{Status: statusVerified}))
+	require.Equal(t, 2, countObjects(t, r, ObjFilter{Container: "c1"}))
+
+	s, err := r.Stats()
+	require.NoError(t, err)
+	require.EqualValues(t, 100, s.PayloadBytes)
+	require.EqualValues(t, 2, s.Ages[0].Count)
+}
+
+func TestSelectorNextObject(t *testing.T) {
+	r := newTestRegistry(t)
+	for range 5 {
+		require.NoError(t, r.AddObject("c", "o", "", "", "h", 0))
+	}
+	require.NoError(t, r.SetObjectStatus(2, statusVerified))
+
+	s := NewObjSelector(r, 2, &ObjFilter{Status: statusCreated}, nil)
+	for _, id := range []uint64{1, 3, 4, 5} {
+		obj := s.NextObject()
+		require.NotNil(t, obj)
+		require.Equal(t, id, obj.ID)
+	}
+
+	require.NoError(t, r.AddObject("c", "o", "", "", "h", 0))
+	select {
+	case obj := <-s.objChan:
+		require.Equal(t, uint64(6), obj.ID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("new object wasn't selected")
+	}
+}
@@ -3,9 +3,10 @@ package registry
 import (
 	"context"
 	"encoding/binary"
-	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
+	"sync/atomic"
 	"time"
 
 	"go.etcd.io/bbolt"
@@ -15,13 +16,15 @@ type ObjRegistry struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 	boltDB *bbolt.DB
+	// batch is set in write-behind mode, writes are committed by it in batches.
+	batch *batchWriter
+	// stats is set if registry stats are reported periodically.
+	stats *statsReporter
+	// purges is incremented on every purge, so that selectors can drop
+	// purged objects they have already cached.
+	purges atomic.Uint64
 }
 
-const (
-	// Indicates that an object was created, but its data wasn't verified yet.
-	statusCreated = "created"
-)
-
 const bucketName = "_object"
 
 // ObjectInfo represents information about neoFS object that has been created
@@ -35,6 +38,16 @@ type ObjectInfo struct {
 	S3Key       string    // Object key in S3
 	Status      string    // Status of the object
 	PayloadHash string    // SHA256 hash of object payload that can be used for verification
+	PayloadSeed string    // Seed of object payload generated by seeded generator
+	PayloadSize int64     // Size of object payload generated by seeded generator
+
+	StatusChangedAt time.Time      // UTC date&time of the last status change
+	History         []StatusChange // Status changes starting from creation
+	Attempts        []Attempt      // Operations performed with the object
+
+	// Payload mode parameters of seeded generator, they are required to
+	// regenerate payload in modes other than random.
+	PayloadParams map[string]string
 }
 
 // NewObjRegistry creates a new instance of object registry that stores information
@@ -42,24 +55,68 @@ type ObjectInfo struct {
 // connection to the database, there may be only one instance of object registry
 // per database file at a time.
 func NewObjRegistry(ctx context.Context, dbFilePath string) *ObjRegistry {
+	objRepository, err := OpenObjRegistry(ctx, dbFilePath)
+	if err != nil {
+		panic(err)
+	}
+	return objRepository
+}
+
+// OpenObjRegistry is the same as NewObjRegistry, but returns error instead of
+// panic, it's intended for use outside of k6.
+func OpenObjRegistry(ctx context.Context, dbFilePath string) (*ObjRegistry, error) {
 	options := bbolt.Options{Timeout: 100 * time.Millisecond, NoSync: true}
 	boltDB, err := bbolt.Open(dbFilePath, os.ModePerm, &options)
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+	if err = ensureIndexes(boltDB); err != nil {
+		_ = boltDB.Close()
+		return nil, err
 	}
 
 	ctx, cancel := context.WithCancel(ctx)
 
-	objRepository := &ObjRegistry{
+	return &ObjRegistry{
 		ctx:    ctx,
 		cancel: cancel,
 		boltDB: boltDB,
-	}
-	return objRepository
+	}, nil
+}
+
+// AddObject adds object in created status. The payloadSize is optional, it's
+// used only in registry stats.
+func (o *ObjRegistry) AddObject(cid, oid, s3Bucket, s3Key, payloadHash string, payloadSize int64) error {
+	return o.addObject(ObjectInfo{
+		CID:         cid,
+		OID:         oid,
+		S3Bucket:    s3Bucket,
+		S3Key:       s3Key,
+		PayloadHash: payloadHash,
+		PayloadSize: payloadSize,
+	})
 }
 
-func (o *ObjRegistry) AddObject(cid, oid, s3Bucket, s3Key, payloadHash string) error {
-	return o.boltDB.Update(func(tx *bbolt.Tx) error {
+// AddSeededObject is the same as AddObject, but instead of payload hash it
+// stores seed, size and optional generator parameters of the payload, so
+// that payload can be regenerated for verification.
+func (o *ObjRegistry) AddSeededObject(cid, oid, s3Bucket, s3Key, payloadSeed string, payloadSize int64, payloadParams map[string]string) error {
+	return o.addObject(ObjectInfo{
+		CID:           cid,
+		OID:           oid,
+		S3Bucket:      s3Bucket,
+		S3Key:         s3Key,
+		PayloadSeed:   payloadSeed,
+		PayloadSize:   payloadSize,
+		PayloadParams: payloadParams,
+	})
+}
+
+func (o *ObjRegistry) addObject(object ObjectInfo) error {
+	// Creation time is set before the write is queued, so that it doesn't
+	// depend on batching delay.
+	object.CreatedAt = time.Now().UTC()
+	return o.update(func(tx *bbolt.Tx) error {
 		b, err := tx.CreateBucketIfNotExists([]byte(bucketName))
 		if err != nil {
 			return err
@@ -70,64 +127,161 @@ func (o *ObjRegistry) AddObject(cid, oid, s3Bucket, s3Key, payloadHash string) e
 			return err
 		}
 
-		object := ObjectInfo{
-			ID:          id,
-			CreatedAt:   time.Now().UTC(),
-			CID:         cid,
-			OID:         oid,
-			S3Bucket:    s3Bucket,
-			S3Key:       s3Key,
-			PayloadHash: payloadHash,
-			Status:      statusCreated,
+		object.ID = id
+		object.Status = statusCreated
+		object.StatusChangedAt = object.CreatedAt
+		object.History = []StatusChange{{Status: statusCreated, At: object.CreatedAt}}
+		if err := b.Put(encodeID(id), encodeObject(&object)); err != nil {
+			return fmt.Errorf("add object %d: %w", id, err)
 		}
-		objectJSON, err := json.Marshal(object)
-		if err != nil {
-			return err
+		if err := indexObject(tx, &object); err != nil {
+			return fmt.Errorf("add object %d: %w", id, err)
 		}
-
-		return b.Put(encodeID(id), objectJSON)
+		return nil
 	})
 }
 
+// SetObjectStatus changes status of the object and records the change in its
+// history. Returns error if the status is unknown or the transition from the
+// current status is not allowed.
 func (o *ObjRegistry) SetObjectStatus(id uint64, newStatus string) error {
-	return o.boltDB.Update(func(tx *bbolt.Tx) error {
-		b, err := tx.CreateBucketIfNotExists([]byte(bucketName))
-		if err != nil {
+	return o.updateObject(id, func(obj *ObjectInfo) error {
+		if err := checkTransition(obj.Status, newStatus); err != nil {
 			return err
 		}
+		now := time.Now().UTC()
+		obj.Status = newStatus
+		obj.StatusChangedAt = now
+		obj.History = appendLimited(obj.History, StatusChange{Status: newStatus, At: now})
+		return nil
+	})
+}
+
+// AddAttempt records the attempt of operation with the object, errMsg is empty
+// for successful attempts. Status of the object is not changed.
+func (o *ObjRegistry) AddAttempt(id uint64, operation, errMsg string) error {
+	return o.updateObject(id, func(obj *ObjectInfo) error {
+		obj.Attempts = appendLimited(obj.Attempts, Attempt{
+			Operation: operation,
+			At:        time.Now().UTC(),
+			Error:     errMsg,
+		})
+		return nil
+	})
+}
 
+// GetObject returns object with the specified ID or nil if there is no such
+// object.
+func (o *ObjRegistry) GetObject(id uint64) (*ObjectInfo, error) {
+	var obj *ObjectInfo
+	err := o.boltDB.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(bucketName))
+		if b == nil {
+			return nil
+		}
 		objBytes := b.Get(encodeID(id))
 		if objBytes == nil {
-			return errors.New("object doesn't exist")
+			return nil
 		}
+		obj = new(ObjectInfo)
+		return decodeObject(objBytes, obj)
+	})
+	return obj, err
+}
 
-		obj := new(ObjectInfo)
-		if err := json.Unmarshal(objBytes, &obj); err != nil {
-			return err
+func (o *ObjRegistry) updateObject(id uint64, update func(*ObjectInfo) error) error {
+	return o.update(func(tx *bbolt.Tx) error {
+		if err := updateObject(tx, id, update); err != nil {
+			return fmt.Errorf("update object %d: %w", id, err)
 		}
-		obj.Status = newStatus
+		return nil
+	})
+}
 
-		objBytes, err = json.Marshal(obj)
+func updateObject(tx *bbolt.Tx, id uint64, update func(*ObjectInfo) error) error {
+	b, err := tx.CreateBucketIfNotExists([]byte(bucketName))
+	if err != nil {
+		return err
+	}
+
+	objBytes := b.Get(encodeID(id))
+	if objBytes == nil {
+		return errors.New("object doesn't exist")
+	}
+
+	obj := new(ObjectInfo)
+	if err := decodeObject(objBytes, obj); err != nil {
+		return err
+	}
+	oldStatus := obj.Status
+	if err := update(obj); err != nil {
+		return err
+	}
+
+	if err := b.Put(encodeID(id), encodeObject(obj)); err != nil {
+		return err
+	}
+	if obj.Status == oldStatus {
+		return nil
+	}
+	if err := removeFromIndex(tx, statusIndexName, oldStatus, id); err != nil {
+		return err
+	}
+	return addToIndex(tx, statusIndexName, obj.Status, id)
+}
+
+func (o *ObjRegistry) DeleteObject(id uint64) error {
+	return o.update(func(tx *bbolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucketName))
 		if err != nil {
 			return err
 		}
-		return b.Put(encodeID(id), objBytes)
+		return deleteObject(tx, b, id)
 	})
 }
 
-func (o *ObjRegistry) DeleteObject(id uint64) error {
-	return o.boltDB.Update(func(tx *bbolt.Tx) error {
-		b, err := tx.CreateBucketIfNotExists([]byte(bucketName))
-		if err != nil {
+// deleteObject removes the object and its index entries, missing objects are
+// ignored.
+func deleteObject(tx *bbolt.Tx, b *bbolt.Bucket, id uint64) error {
+	objBytes := b.Get(encodeID(id))
+	if objBytes == nil {
+		return nil
+	}
+	var obj ObjectInfo
+	if err := decodeObject(objBytes, &obj); err == nil {
+		if err := unindexObject(tx, &obj); err != nil {
 			return err
 		}
+	}
+	return b.Delete(encodeID(id))
+}
 
-		return b.Delete(encodeID(id))
-	})
+// update runs the write transaction. In write-behind mode the write is queued
+// and nil is returned, errors are reported in neofs_registry_write_errors
+// metric then.
+func (o *ObjRegistry) update(op func(*bbolt.Tx) error) error {
+	if o.batch != nil {
+		return o.batch.write(op)
+	}
+	return o.boltDB.Update(op)
+}
+
+// Flush waits until all queued writes are committed in write-behind mode.
+func (o *ObjRegistry) Flush() {
+	if o.batch != nil {
+		o.batch.flush()
+	}
 }
 
+// Close commits queued writes and closes the registry.
 func (o *ObjRegistry) Close() error {
+	if o.batch != nil {
+		o.batch.close()
+	}
 	o.cancel()
+	if o.stats != nil {
+		<-o.stats.done
+	}
 	return o.boltDB.Close()
 }
 
@@ -2,16 +2,52 @@ package registry
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.etcd.io/bbolt"
 )
 
+// Selector modes.
+const (
+	// Objects are selected in ascending ID order, selector waits for new
+	// objects at the end.
+	selectorModeForward = "forward"
+	// Same as forward, but selector starts from the beginning at the end.
+	selectorModeLoop = "loop"
+	// Objects are selected in descending ID order starting from the most
+	// recent one, selector starts from the most recent object at the end.
+	selectorModeRecent = "recent"
+	// Objects are selected randomly with uniform distribution.
+	selectorModeRandom = "random"
+	// Objects are selected randomly with Zipf distribution by recency, so
+	// that the most recent objects are the hottest.
+	selectorModeZipf = "zipf"
+)
+
+// SelectorParams are parameters of the object selection order.
+type SelectorParams struct {
+	Mode  string
+	ZipfS float64 // Zipf exponent, it should be greater than 1
+}
+
+// idsRefreshInterval is the interval of reloading IDs of matching objects
+// by random selectors.
+const idsRefreshInterval = 10 * time.Second
+
+// maxRandomAttempts is the number of random picks made before the random
+// selector reloads IDs, picked objects may be deleted or changed since IDs
+// were loaded.
+const maxRandomAttempts = 10
+
 type ObjFilter struct {
-	Status string
-	Age    int
+	Status    string
+	Age       int
+	Container string // Container ID of gRPC/HTTP objects
+	Bucket    string // Bucket name of S3 objects
 }
 
 type ObjSelector struct {
@@ -19,15 +55,40 @@ type ObjSelector struct {
 	objChan   chan *ObjectInfo
 	boltDB    *bbolt.DB
 	filter    *ObjFilter
+	params    SelectorParams
 	cacheSize int
+
+	mu sync.Mutex
+
+	// State of random selectors. Picks are made from the snapshot of IDs
+	// without locks, snapshot is replaced by a single loader at a time.
+	ids        atomic.Pointer[idsSnapshot]
+	loadMu     sync.Mutex
+	refreshing atomic.Bool
+	// zipfMu protects rnd used by Zipf generators of snapshots.
+	zipfMu sync.Mutex
+	rnd    *rand.Rand
+
+	// Objects cached before the last purge are checked before they are
+	// returned.
+	purges     *atomic.Uint64
+	seenPurges uint64
+	recheck    int
+}
+
+// idsSnapshot is a list of IDs of matching objects in ascending order.
+type idsSnapshot struct {
+	ids      []uint64
+	loadedAt time.Time
+	zipf     *rand.Zipf
 }
 
 // objectSelectCache is the default maximum size of a batch to select from DB.
 const objectSelectCache = 1000
 
 // NewObjSelector creates a new instance of object selector that can iterate over
-// objects in the specified registry.
-func NewObjSelector(registry *ObjRegistry, selectionSize int, filter *ObjFilter) *ObjSelector {
+// objects in the specified registry. Nil params mean forward selection.
+func NewObjSelector(registry *ObjRegistry, selectionSize int, filter *ObjFilter, params *SelectorParams) *ObjSelector {
 	if selectionSize <= 0 {
 		selectionSize = objectSelectCache
 	}
@@ -35,57 +96,230 @@ func NewObjSelector(registry *ObjRegistry, selectionSize int, filter *ObjFilter)
 		ctx:       registry.ctx,
 		boltDB:    registry.boltDB,
 		filter:    filter,
-		objChan:   make(chan *ObjectInfo, selectionSize*2),
 		cacheSize: selectionSize,
+		purges:    &registry.purges,
+	}
+	if params != nil {
+		objSelector.params = *params
 	}
 
-	go objSelector.selectLoop()
+	switch objSelector.params.Mode {
+	case selectorModeRandom, selectorModeZipf:
+		objSelector.rnd = rand.New(rand.NewPCG(uint64(time.Now().UnixNano()), 0))
+	default:
+		objSelector.objChan = make(chan *ObjectInfo, selectionSize*2)
+		go objSelector.selectLoop()
+	}
 
 	return objSelector
 }
 
 // NextObject returns the next object from the registry that matches filter of
-// the selector. NextObject only roams forward from the current position of the
-// selector. If there are no objects that match the filter, blocks until one of
-// the following happens:
+// the selector. In forward mode, NextObject only roams forward from the current
+// position of the selector. If there are no objects that match the filter,
+// blocks until one of the following happens:
 //   - a "new" next object is available;
 //   - underlying registry context is done, nil objects will be returned on the
 //     currently blocked and every further NextObject calls.
+//
+// Loop and recent modes start from the beginning when all objects are
+// selected, so they block only if there are no matching objects at all.
+// Random and zipf modes never block, they return nil if there are no matching
+// objects.
 func (o *ObjSelector) NextObject() *ObjectInfo {
-	return <-o.objChan
+	if o.objChan == nil {
+		return o.nextRandom()
+	}
+	for {
+		obj := <-o.objChan
+		if obj == nil || !o.purged(obj) {
+			return obj
+		}
+	}
 }
 
-// Count returns total number of objects that match filter of the selector.
-func (o *ObjSelector) Count() (int, error) {
-	var count = 0
+// purged returns true if the object was removed by purge after it had been
+// cached. All objects that may have been cached before the purge are read
+// again from the registry.
+func (o *ObjSelector) purged(obj *ObjectInfo) bool {
+	o.mu.Lock()
+	if purges := o.purges.Load(); purges != o.seenPurges {
+		o.seenPurges = purges
+		o.recheck = cap(o.objChan) + o.cacheSize
+	}
+	if o.recheck == 0 {
+		o.mu.Unlock()
+		return false
+	}
+	o.recheck--
+	o.mu.Unlock()
+
+	var exists bool
 	err := o.boltDB.View(func(tx *bbolt.Tx) error {
-		b := tx.Bucket([]byte(bucketName))
-		if b == nil {
+		if objects := tx.Bucket([]byte(bucketName)); objects != nil {
+			exists = objects.Get(encodeID(obj.ID)) != nil
+		}
+		return nil
+	})
+	if err != nil {
+		panic(fmt.Errorf("fetching objects failed: %w", err))
+	}
+	return !exists
+}
+
+// nextRandom picks random object from the snapshot of IDs of matching
+// objects. Outdated snapshot is reloaded in background, while picks are made
+// from the current one. If picked objects don't match the filter anymore,
+// the snapshot is reloaded immediately.
+func (o *ObjSelector) nextRandom() *ObjectInfo {
+	if o.ctx.Err() != nil {
+		return nil
+	}
+
+	snap := o.ids.Load()
+	if snap == nil {
+		snap = o.mustReloadIDs(nil)
+	} else if time.Since(snap.loadedAt) > idsRefreshInterval && o.refreshing.CompareAndSwap(false, true) {
+		go func() {
+			defer o.refreshing.Store(false)
+			// Errors are ignored, the current snapshot is used until
+			// the next attempt.
+			_, _ = o.reloadIDs(snap)
+		}()
+	}
+
+	for reloaded := false; ; reloaded = true {
+		if len(snap.ids) == 0 {
 			return nil
 		}
 
-		return b.ForEach(func(_, objBytes []byte) error {
-			if objBytes != nil {
-				var obj ObjectInfo
-				if err := json.Unmarshal(objBytes, &obj); err != nil {
-					// Ignore malformed objects
-					return nil
-				}
-				if o.filter.match(obj) {
-					count++
+		for range maxRandomAttempts {
+			id := o.pick(snap)
+
+			var obj *ObjectInfo
+			err := o.boltDB.View(func(tx *bbolt.Tx) error {
+				if objects := tx.Bucket([]byte(bucketName)); objects != nil {
+					obj = readObject(objects, encodeID(id))
 				}
+				return nil
+			})
+			if err != nil {
+				panic(fmt.Errorf("fetching objects failed: %w", err))
+			}
+			if obj != nil && o.filter.match(*obj) {
+				return obj
 			}
+		}
+		if reloaded {
 			return nil
+		}
+		snap = o.mustReloadIDs(snap)
+	}
+}
+
+// pick returns random ID from the snapshot.
+func (o *ObjSelector) pick(snap *idsSnapshot) uint64 {
+	if snap.zipf == nil {
+		return snap.ids[rand.IntN(len(snap.ids))]
+	}
+	o.zipfMu.Lock()
+	rank := snap.zipf.Uint64()
+	o.zipfMu.Unlock()
+	// rank 0 is the most recent object
+	return snap.ids[len(snap.ids)-1-int(rank)]
+}
+
+func (o *ObjSelector) mustReloadIDs(old *idsSnapshot) *idsSnapshot {
+	snap, err := o.reloadIDs(old)
+	if err != nil {
+		panic(fmt.Errorf("fetching objects failed: %w", err))
+	}
+	return snap
+}
+
+// reloadIDs loads IDs of all matching objects unless the snapshot has been
+// replaced since old one was taken, then the current snapshot is returned.
+func (o *ObjSelector) reloadIDs(old *idsSnapshot) (*idsSnapshot, error) {
+	o.loadMu.Lock()
+	defer o.loadMu.Unlock()
+
+	if cur := o.ids.Load(); cur != old {
+		return cur, nil
+	}
+
+	snap := new(idsSnapshot)
+	err := o.boltDB.View(func(tx *bbolt.Tx) error {
+		if o.filter.indexed() {
+			snap.ids = scanIDs(tx, o.filter)
+			return nil
+		}
+		return scanObjects(tx, o.filter, 0, func(obj *ObjectInfo) bool {
+			snap.ids = append(snap.ids, obj.ID)
+			return true
 		})
 	})
-	return count, err
+	if err != nil {
+		return nil, err
+	}
+	snap.loadedAt = time.Now()
+
+	if o.params.Mode == selectorModeZipf && len(snap.ids) > 0 {
+		snap.zipf = rand.NewZipf(o.rnd, o.params.ZipfS, 1, uint64(len(snap.ids)-1))
+	}
+	o.ids.Store(snap)
+	return snap, nil
+}
+
+// Count returns total number of objects that match filter of the selector.
+// Objects are counted with index counters unless filter has age, both status
+// and location or both container and bucket.
+func (o *ObjSelector) Count() (int, error) {
+	var count uint64
+	err := o.boltDB.View(func(tx *bbolt.Tx) error {
+		loc := o.filter.location()
+		switch {
+		case !o.filter.indexed():
+			return scanObjects(tx, o.filter, 0, func(*ObjectInfo) bool {
+				count++
+				return true
+			})
+		case loc != "":
+			count = readCounter(tx, locationIndexName, loc)
+		case o.filter.Status != "":
+			count = readCounter(tx, statusIndexName, o.filter.Status)
+		default:
+			for _, n := range readCounters(tx, statusIndexName) {
+				count += n
+			}
+		}
+		return nil
+	})
+	return int(count), err
 }
 
 func (o *ObjSelector) selectLoop() {
 	cache := make([]*ObjectInfo, 0, o.cacheSize)
-	var lastID uint64
 	defer close(o.objChan)
 
+	// Objects with IDs up to lastID are handled. Objects that are too young
+	// for the filter are selected later, so lastID stops before the first
+	// of them and older objects after it are remembered in ahead.
+	var (
+		lastID uint64
+		ahead  = make(map[uint64]struct{})
+	)
+
+	reverse := o.params.Mode == selectorModeRecent
+	restart := reverse || o.params.Mode == selectorModeLoop
+
+	// Age is checked separately in forward scans, see above.
+	filter := o.filter
+	if !reverse && o.filter.Age != 0 {
+		noAge := *o.filter
+		noAge.Age = 0
+		filter = &noAge
+	}
+
 	for {
 		select {
 		case <-o.ctx.Done():
@@ -93,54 +327,50 @@ func (o *ObjSelector) selectLoop() {
 		default:
 		}
 
-		// cache the objects
+		// cache the objects starting from the object next to the last
+		// handled one
+		var firstYoung uint64
 		err := o.boltDB.View(func(tx *bbolt.Tx) error {
-			b := tx.Bucket([]byte(bucketName))
-			if b == nil {
-				return nil
+			if reverse {
+				return scanObjectsReverse(tx, filter, lastID, func(obj *ObjectInfo) bool {
+					cache = append(cache, obj)
+					return len(cache) != o.cacheSize
+				})
 			}
-
-			c := b.Cursor()
-
-			// Establish the start position for searching the next object:
-			// If we should go from the beginning (lastID=0), then we start
-			// from the first element. Otherwise, we start from the last
-			// handled ID + 1.
-			var keyBytes, objBytes []byte
-			if lastID == 0 {
-				keyBytes, objBytes = c.First()
-			} else {
-				keyBytes, objBytes = c.Seek(encodeID(lastID))
-				if keyBytes != nil && decodeID(keyBytes) == lastID {
-					keyBytes, objBytes = c.Next()
-				}
-			}
-
-			// Iterate over objects to find the next object matching the filter.
-			for ; keyBytes != nil && len(cache) != o.cacheSize; keyBytes, objBytes = c.Next() {
-				if objBytes != nil {
-					var obj ObjectInfo
-					if err := json.Unmarshal(objBytes, &obj); err != nil {
-						// Ignore malformed objects for now. Maybe it should be panic?
-						continue
-					}
-
-					if o.filter.match(obj) {
-						cache = append(cache, &obj)
+			now := time.Now().UTC()
+			return scanObjects(tx, filter, lastID, func(obj *ObjectInfo) bool {
+				if o.filter.tooYoung(*obj, now) {
+					if firstYoung == 0 {
+						firstYoung = obj.ID
 					}
+					return true
 				}
-			}
-
-			if len(cache) > 0 {
-				lastID = cache[len(cache)-1].ID
-			}
-
-			return nil
+				if _, ok := ahead[obj.ID]; ok {
+					return true
+				}
+				if firstYoung != 0 {
+					ahead[obj.ID] = struct{}{}
+				}
+				cache = append(cache, obj)
+				return len(cache) != o.cacheSize
+			})
 		})
 		if err != nil {
 			panic(fmt.Errorf("fetching objects failed: %w", err))
 		}
 
+		switch {
+		case firstYoung != 0:
+			lastID = firstYoung - 1
+		case len(cache) > 0:
+			lastID = cache[len(cache)-1].ID
+		}
+		for id := range ahead {
+			if id <= lastID {
+				delete(ahead, id)
+			}
+		}
+
 		for _, obj := range cache {
 			select {
 			case <-o.ctx.Done():
@@ -149,6 +379,14 @@ func (o *ObjSelector) selectLoop() {
 			}
 		}
 
+		// All objects are selected, start from the beginning
+		if restart && len(cache) != o.cacheSize && lastID != 0 {
+			lastID = 0
+			clear(ahead)
+			cache = cache[:0]
+			continue
+		}
+
 		if len(cache) != o.cacheSize {
 			// no more objects, wait a little; the logic could be improved.
 			select {
@@ -163,15 +401,148 @@ func (o *ObjSelector) selectLoop() {
 	}
 }
 
+// scanObjects calls f for objects with ID greater than afterID that match
+// the filter in ascending ID order until f returns false. Objects are taken
+// from location or status index if filter has them, so that only matching
+// objects are decoded. IDs don't necessarily grow with creation time (e.g.
+// imported objects keep their creation time), so objects that are too young
+// for the filter are skipped rather than stop the scan.
+func scanObjects(tx *bbolt.Tx, filter *ObjFilter, afterID uint64, f func(*ObjectInfo) bool) error {
+	objects, c := filterCursor(tx, filter)
+	if c == nil {
+		return nil
+	}
+
+	var keyBytes []byte
+	if afterID == 0 {
+		keyBytes, _ = c.First()
+	} else {
+		keyBytes, _ = c.Seek(encodeID(afterID))
+		if keyBytes != nil && decodeID(keyBytes) == afterID {
+			keyBytes, _ = c.Next()
+		}
+	}
+
+	for ; keyBytes != nil; keyBytes, _ = c.Next() {
+		obj := readObject(objects, keyBytes)
+		if obj != nil && filter.match(*obj) && !f(obj) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// scanIDs returns IDs of objects from the cursor of filterCursor in ascending
+// order without decoding objects. It's only correct for indexed filters.
+func scanIDs(tx *bbolt.Tx, filter *ObjFilter) []uint64 {
+	_, c := filterCursor(tx, filter)
+	if c == nil {
+		return nil
+	}
+
+	var ids []uint64
+	for keyBytes, _ := c.First(); keyBytes != nil; keyBytes, _ = c.Next() {
+		ids = append(ids, decodeID(keyBytes))
+	}
+	return ids
+}
+
+// scanObjectsReverse is the same as scanObjects, but it goes in descending
+// ID order from objects with ID less than beforeID or from the last object
+// if beforeID is 0.
+func scanObjectsReverse(tx *bbolt.Tx, filter *ObjFilter, beforeID uint64, f func(*ObjectInfo) bool) error {
+	objects, c := filterCursor(tx, filter)
+	if c == nil {
+		return nil
+	}
+
+	var keyBytes []byte
+	if beforeID == 0 {
+		keyBytes, _ = c.Last()
+	} else {
+		keyBytes, _ = c.Seek(encodeID(beforeID))
+		if keyBytes == nil {
+			keyBytes, _ = c.Last()
+		} else {
+			keyBytes, _ = c.Prev()
+		}
+	}
+
+	for ; keyBytes != nil; keyBytes, _ = c.Prev() {
+		obj := readObject(objects, keyBytes)
+		if obj != nil && filter.match(*obj) && !f(obj) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// filterCursor returns bucket of objects and cursor over location or status
+// index if filter has them or over all objects otherwise. Cursor is nil if
+// there are no matching objects.
+func filterCursor(tx *bbolt.Tx, filter *ObjFilter) (*bbolt.Bucket, *bbolt.Cursor) {
+	objects := tx.Bucket([]byte(bucketName))
+	if objects == nil {
+		return nil, nil
+	}
+
+	var b *bbolt.Bucket
+	switch loc := filter.location(); {
+	case loc != "":
+		b = indexBucket(tx, locationIndexName, loc)
+	case filter.Status != "":
+		b = indexBucket(tx, statusIndexName, filter.Status)
+	default:
+		b = objects
+	}
+	if b == nil {
+		return nil, nil
+	}
+	return objects, b.Cursor()
+}
+
+// readObject returns decoded object with the specified key or nil if it
+// doesn't exist or is malformed.
+func readObject(objects *bbolt.Bucket, keyBytes []byte) *ObjectInfo {
+	objBytes := objects.Get(keyBytes)
+	if objBytes == nil {
+		return nil
+	}
+	var obj ObjectInfo
+	if err := decodeObject(objBytes, &obj); err != nil {
+		// Ignore malformed objects for now. Maybe it should be panic?
+		return nil
+	}
+	return &obj
+}
+
 func (f *ObjFilter) match(o ObjectInfo) bool {
 	if f.Status != "" && f.Status != o.Status {
 		return false
 	}
-	if f.Age != 0 {
-		objAge := time.Now().UTC().Sub(o.CreatedAt).Seconds()
-		if objAge < float64(f.Age) {
-			return false
-		}
+	if f.Container != "" && f.Container != o.CID {
+		return false
 	}
-	return true
+	if f.Bucket != "" && f.Bucket != o.S3Bucket {
+		return false
+	}
+	return !f.tooYoung(o, time.Now().UTC())
+}
+
+// indexed checks whether objects matching the filter are exactly the objects
+// of the index selected by filterCursor (or all objects), so they can be found
+// without decoding.
+func (f *ObjFilter) indexed() bool {
+	return f.Age == 0 &&
+		(f.Container == "" || f.Bucket == "") &&
+		(f.Status == "" || f.location() == "")
+}
+
+func (f *ObjFilter) tooYoung(o ObjectInfo, now time.Time) bool {
+	return f.Age != 0 && now.Sub(o.CreatedAt).Seconds() < float64(f.Age)
+}
+
+// location returns object location of the filter used in location index.
+func (f *ObjFilter) location() string {
+	return objectLocation(f.Container, f.Bucket)
 }
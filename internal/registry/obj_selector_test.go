@@ -0,0 +1,217 @@
+package registry
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func TestSelectorModes(t *testing.T) {
+	newRegistry := func(t *testing.T, n int) *ObjRegistry {
+		r := newTestRegistry(t)
+		for range n {
+			require.NoError(t, r.AddObject("c", "o", "", "", "h", 0))
+		}
+		require.NoError(t, r.SetObjectStatus(2, statusVerified))
+		return r
+	}
+	nextIDs := func(s *ObjSelector, n int) []uint64 {
+		ids := make([]uint64, n)
+		for i := range ids {
+			ids[i] = s.NextObject().ID
+		}
+		return ids
+	}
+	filter := &ObjFilter{Status: statusCreated}
+
+	t.Run("loop", func(t *testing.T) {
+		r := newRegistry(t, 5)
+		s := NewObjSelector(r, 3, filter, &SelectorParams{Mode: selectorModeLoop})
+		require.Equal(t, []uint64{1, 3, 4, 5, 1, 3, 4, 5, 1}, nextIDs(s, 9))
+	})
+
+	t.Run("recent", func(t *testing.T) {
+		r := newRegistry(t, 5)
+		s := NewObjSelector(r, 3, filter, &SelectorParams{Mode: selectorModeRecent})
+		require.Equal(t, []uint64{5, 4, 3, 1, 5, 4, 3, 1}, nextIDs(s, 8))
+	})
+
+	t.Run("random", func(t *testing.T) {
+		r := newRegistry(t, 5)
+		s := NewObjSelector(r, 0, filter, &SelectorParams{Mode: selectorModeRandom})
+
+		counts := make(map[uint64]int)
+		for _, id := range nextIDs(s, 1000) {
+			counts[id]++
+		}
+		require.Len(t, counts, 4)
+		require.NotContains(t, counts, uint64(2))
+
+		// objects that don't match anymore are not selected
+		for _, id := range []uint64{1, 3, 4} {
+			require.NoError(t, r.SetObjectStatus(id, statusVerified))
+		}
+		for range 100 {
+			require.Equal(t, uint64(5), s.NextObject().ID)
+		}
+
+		require.NoError(t, r.SetObjectStatus(5, statusVerified))
+		require.Nil(t, s.NextObject())
+	})
+
+	t.Run("zipf", func(t *testing.T) {
+		r := newRegistry(t, 100)
+		s := NewObjSelector(r, 0, &ObjFilter{}, &SelectorParams{Mode: selectorModeZipf, ZipfS: 1.5})
+
+		counts := make(map[uint64]int)
+		for _, id := range nextIDs(s, 10000) {
+			counts[id]++
+		}
+		require.Greater(t, counts[100], counts[99])
+		require.Greater(t, counts[99], counts[50])
+		require.Greater(t, counts[100], 2000)
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		r := newRegistry(t, 100)
+		s := NewObjSelector(r, 0, &ObjFilter{}, &SelectorParams{Mode: selectorModeZipf, ZipfS: 1.1})
+
+		var wg sync.WaitGroup
+		for range 8 {
+			wg.Go(func() {
+				for range 1000 {
+					require.NotNil(t, s.NextObject())
+				}
+			})
+		}
+		wg.Wait()
+	})
+
+	t.Run("background refresh", func(t *testing.T) {
+		r := newRegistry(t, 5)
+		s := NewObjSelector(r, 0, filter, &SelectorParams{Mode: selectorModeRandom})
+		require.NotNil(t, s.NextObject())
+
+		require.NoError(t, r.AddObject("c", "o", "", "", "h", 0))
+		old := s.ids.Load()
+		old.loadedAt = time.Now().Add(-2 * idsRefreshInterval)
+
+		// the outdated snapshot is still used until refresh is done
+		require.NotNil(t, s.NextObject())
+		require.Eventually(t, func() bool {
+			return s.ids.Load() != old
+		}, time.Second, time.Millisecond)
+		require.Len(t, s.ids.Load().ids, 5)
+	})
+
+	t.Run("random without objects", func(t *testing.T) {
+		r := newTestRegistry(t)
+		s := NewObjSelector(r, 0, filter, &SelectorParams{Mode: selectorModeZipf, ZipfS: 1.1})
+		require.Nil(t, s.NextObject())
+	})
+}
+
+func TestParseSelectorParams(t *testing.T) {
+	p, err := parseSelectorParams(nil)
+	require.NoError(t, err)
+	require.Equal(t, SelectorParams{Mode: selectorModeForward}, *p)
+
+	p, err = parseSelectorParams(map[string]string{"mode": "zipf"})
+	require.NoError(t, err)
+	require.Equal(t, SelectorParams{Mode: selectorModeZipf, ZipfS: 1.1}, *p)
+
+	for _, params := range []map[string]string{
+		{"mode": "unknown"},
+		{"mode": "zipf", "zipf_s": "1"},
+	} {
+		_, err = parseSelectorParams(params)
+		require.Error(t, err, params)
+	}
+}
+
+// importOldObject imports JSON Lines record of the object created age ago,
+// it gets ID greater than IDs of all existing objects.
+func importOldObject(t *testing.T, r *ObjRegistry, age time.Duration) {
+	createdAt := time.Now().UTC().Add(-age).Format(time.RFC3339Nano)
+	n, err := r.Import(strings.NewReader(`{"c_id":"c","o_id":"old","created_at":"`+createdAt+`"}`+"\n"), FormatJSONL)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+}
+
+func TestSelectorAgeOutOfOrder(t *testing.T) {
+	r := newTestRegistry(t)
+	require.NoError(t, r.AddObject("c", "young", "", "", "h", 0))
+	importOldObject(t, r, 48*time.Hour)
+
+	for _, filter := range []ObjFilter{{Age: 3600}, {Age: 3600, Status: statusCreated}, {Age: 3600, Container: "c"}} {
+		require.Equal(t, 1, countObjects(t, r, filter), filter)
+		require.Equal(t, []uint64{2}, selectIDs(t, r, filter), filter)
+	}
+
+	t.Run("random", func(t *testing.T) {
+		s := NewObjSelector(r, 0, &ObjFilter{Age: 3600}, &SelectorParams{Mode: selectorModeRandom})
+		obj := s.NextObject()
+		require.NotNil(t, obj)
+		require.EqualValues(t, 2, obj.ID)
+	})
+
+	t.Run("forward", func(t *testing.T) {
+		s := NewObjSelector(r, 0, &ObjFilter{Age: 2}, nil)
+		require.EqualValues(t, 2, s.NextObject().ID)
+		// Young object is selected when it gets old enough, the older one
+		// isn't selected again.
+		require.EqualValues(t, 1, s.NextObject().ID)
+		select {
+		case obj := <-s.objChan:
+			require.Failf(t, "unexpected object", "%d", obj.ID)
+		case <-time.After(1500 * time.Millisecond):
+		}
+	})
+}
+
+func TestReloadIDs(t *testing.T) {
+	r := newTestRegistry(t)
+	require.NoError(t, r.AddObject("c1", "o", "", "", "h", 0))
+	require.NoError(t, r.AddObject("c1", "", "b1", "k", "h", 0))
+	require.NoError(t, r.AddObject("c2", "o", "", "", "h", 0))
+	require.NoError(t, r.AddObject("c2", "", "b1", "k", "h", 0))
+	require.NoError(t, r.SetObjectStatus(1, statusVerified))
+	require.NoError(t, r.SetObjectStatus(4, statusVerified))
+
+	reload := func(filter ObjFilter) []uint64 {
+		s := &ObjSelector{boltDB: r.boltDB, filter: &filter, params: SelectorParams{Mode: selectorModeRandom}}
+		snap, err := s.reloadIDs(nil)
+		require.NoError(t, err)
+		return snap.ids
+	}
+
+	for _, tc := range []struct {
+		filter  ObjFilter
+		indexed bool
+		ids     []uint64
+	}{
+		{filter: ObjFilter{}, indexed: true, ids: []uint64{1, 2, 3, 4}},
+		{filter: ObjFilter{Status: statusCreated}, indexed: true, ids: []uint64{2, 3}},
+		{filter: ObjFilter{Container: "c2"}, indexed: true, ids: []uint64{3}},
+		{filter: ObjFilter{Bucket: "b1"}, indexed: true, ids: []uint64{2, 4}},
+		{filter: ObjFilter{Container: "c1", Bucket: "b1"}, ids: []uint64{2}},
+		{filter: ObjFilter{Status: statusVerified, Bucket: "b1"}, ids: []uint64{4}},
+		{filter: ObjFilter{Age: 3600}},
+	} {
+		require.Equal(t, tc.indexed, tc.filter.indexed(), tc.filter)
+		require.Equal(t, tc.ids, reload(tc.filter), tc.filter)
+		require.Equal(t, tc.ids, selectIDs(t, r, tc.filter), tc.filter)
+		require.Equal(t, len(tc.ids), countObjects(t, r, tc.filter), tc.filter)
+	}
+
+	// Objects aren't decoded for indexed filters.
+	require.NoError(t, r.boltDB.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket([]byte(bucketName)).Put(encodeID(3), []byte{0xff})
+	}))
+	require.Equal(t, []uint64{1, 2, 3, 4}, reload(ObjFilter{}))
+	require.Equal(t, []uint64{1, 2, 4}, selectIDs(t, r, ObjFilter{}))
+}
@@ -0,0 +1,99 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.etcd.io/bbolt"
+)
+
+// purgeBatchSize is the number of objects purged in a single transaction.
+const purgeBatchSize = 1000
+
+// compactTxSize is the maximum size of a transaction writing compacted
+// database.
+const compactTxSize = 64 << 20
+
+// PurgeObjects removes objects matching the filter from the registry and
+// returns the number of removed objects. The filter has the same keys as
+// selector filter, but it must not be empty.
+func (o *ObjRegistry) PurgeObjects(filter map[string]string) (int, error) {
+	objFilter, err := parseFilter(filter)
+	if err != nil {
+		return 0, err
+	}
+	return o.Purge(objFilter)
+}
+
+// Purge removes objects matching the filter from the registry. Objects
+// already cached by selectors are checked again before they are returned,
+// so purged objects are not selected. Removed objects free pages in the
+// database file, but the file doesn't shrink until it is compacted.
+func (o *ObjRegistry) Purge(filter *ObjFilter) (int, error) {
+	if *filter == (ObjFilter{}) {
+		return 0, errors.New("purge filter is empty")
+	}
+
+	o.Flush()
+	defer o.purges.Add(1)
+
+	var total int
+	for {
+		var n int
+		err := o.boltDB.Update(func(tx *bbolt.Tx) error {
+			var ids []uint64
+			err := scanObjects(tx, filter, 0, func(obj *ObjectInfo) bool {
+				ids = append(ids, obj.ID)
+				return len(ids) != purgeBatchSize
+			})
+			if err != nil || len(ids) == 0 {
+				return err
+			}
+
+			b := tx.Bucket([]byte(bucketName))
+			for _, id := range ids {
+				if err := deleteObject(tx, b, id); err != nil {
+					return err
+				}
+			}
+			n = len(ids)
+			return nil
+		})
+		total += n
+		if err != nil || n < purgeBatchSize {
+			return total, err
+		}
+	}
+}
+
+// Compact writes compacted copy of the registry database to dstPath, which
+// must not exist. Registry, its selectors and VUs keep using the current
+// file, it can be replaced with the compacted one when registry is not in
+// use.
+func (o *ObjRegistry) Compact(dstPath string) error {
+	if _, err := os.Stat(dstPath); err == nil {
+		return fmt.Errorf("compaction destination already exists: '%s'", dstPath)
+	}
+	if filepath.Clean(dstPath) == filepath.Clean(o.boltDB.Path()) {
+		return errors.New("registry can't be compacted into itself")
+	}
+
+	o.Flush()
+	dst, err := bbolt.Open(dstPath, os.ModePerm, &bbolt.Options{NoSync: true})
+	if err != nil {
+		return err
+	}
+	err = bbolt.Compact(dst, o.boltDB, compactTxSize)
+	if err == nil {
+		err = dst.Sync()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(dstPath)
+	}
+	return err
+}
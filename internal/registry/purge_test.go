@@ -0,0 +1,100 @@
+package registry
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurge(t *testing.T) {
+	r := newTestRegistry(t)
+	for range 5 {
+		require.NoError(t, r.AddObject("c1", "o", "", "", "h", 0))
+	}
+	require.NoError(t, r.AddObject("", "", "b1", "k", "h", 0))
+	require.NoError(t, r.SetObjectStatus(2, statusDeleted))
+	require.NoError(t, r.SetObjectStatus(6, statusDeleted))
+
+	_, err := r.PurgeObjects(map[string]string{})
+	require.Error(t, err)
+
+	n, err := r.PurgeObjects(map[string]string{"status": statusDeleted})
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, []uint64{1, 3, 4, 5}, selectIDs(t, r, ObjFilter{}))
+	require.Equal(t, 0, countObjects(t, r, ObjFilter{Bucket: "b1"}))
+	require.Equal(t, 4, countObjects(t, r, ObjFilter{Container: "c1"}))
+
+	n, err = r.Purge(&ObjFilter{Age: 3600})
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	n, err = r.Purge(&ObjFilter{Container: "c1"})
+	require.NoError(t, err)
+	require.Equal(t, 4, n)
+	require.Equal(t, 0, countObjects(t, r, ObjFilter{}))
+}
+
+func TestPurgeAgeAfterImport(t *testing.T) {
+	r := newTestRegistry(t)
+	require.NoError(t, r.AddObject("c", "young", "", "", "h", 0))
+	importOldObject(t, r, 48*time.Hour)
+	require.NoError(t, r.AddObject("c", "young", "", "", "h", 0))
+
+	n, err := r.Purge(&ObjFilter{Age: 3600})
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, []uint64{1, 3}, selectIDs(t, r, ObjFilter{}))
+	require.Equal(t, 2, countObjects(t, r, ObjFilter{Container: "c"}))
+}
+
+func TestPurgeCachedBySelector(t *testing.T) {
+	r := newTestRegistry(t)
+	for range 10 {
+		require.NoError(t, r.AddObject("c1", "o", "", "", "h", 0))
+	}
+	s := NewObjSelector(r, 0, &ObjFilter{Status: statusCreated}, nil)
+	require.EqualValues(t, 1, s.NextObject().ID)
+
+	n, err := r.Purge(&ObjFilter{Status: statusCreated, Container: "c1"})
+	require.NoError(t, err)
+	require.Equal(t, 10, n)
+
+	require.NoError(t, r.AddObject("c1", "o", "", "", "h", 0))
+	require.EqualValues(t, 11, s.NextObject().ID)
+}
+
+func TestCompact(t *testing.T) {
+	r := newTestRegistry(t)
+	for range 100 {
+		require.NoError(t, r.AddObject("c1", "o", "", "", "h", 0))
+	}
+	require.NoError(t, r.SetObjectStatus(100, statusVerified))
+	_, err := r.Purge(&ObjFilter{Status: statusCreated})
+	require.NoError(t, err)
+
+	// Compaction doesn't affect selectors of the registry
+	s := NewObjSelector(r, 0, &ObjFilter{}, nil)
+	dstPath := filepath.Join(t.TempDir(), "compacted.bolt")
+	require.NoError(t, r.Compact(dstPath))
+	require.Error(t, r.Compact(dstPath))
+	require.Error(t, r.Compact(r.boltDB.Path()))
+	require.EqualValues(t, 100, s.NextObject().ID)
+
+	c, err := OpenObjRegistry(context.Background(), dstPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = c.Close() })
+
+	obj, err := c.GetObject(100)
+	require.NoError(t, err)
+	require.Equal(t, statusVerified, obj.Status)
+	require.Equal(t, 1, countObjects(t, c, ObjFilter{Status: statusVerified}))
+
+	// IDs continue from the last one
+	require.NoError(t, c.AddObject("c1", "o", "", "", "h", 0))
+	require.Equal(t, []uint64{100, 101}, selectIDs(t, c, ObjFilter{}))
+	require.WithinDuration(t, time.Now(), obj.CreatedAt, time.Minute)
+}
@@ -5,8 +5,11 @@ import (
 	"reflect"
 	"strconv"
 	"sync"
+	"time"
 
+	"github.com/nspcc-dev/xk6-neofs/internal/stats"
 	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/metrics"
 )
 
 // RootModule is the global module object type. It is instantiated once per test
@@ -27,6 +30,35 @@ type Registry struct {
 	root *RootModule
 }
 
+// VUObjRegistry is an object registry opened by the VU. It reports metrics
+// of write-behind mode and registry stats on behalf of the VU.
+type VUObjRegistry struct {
+	*ObjRegistry
+	vu modules.VU
+}
+
+// VUObjSelector is an object selector used by the VU. It reports registry
+// metrics the same way as VUObjRegistry, so that they are reported by
+// scenarios that only select objects.
+type VUObjSelector struct {
+	*ObjSelector
+	vu       modules.VU
+	registry *ObjRegistry
+}
+
+// defaultBatchInterval is the maximum time writes wait in the queue in
+// write-behind mode.
+const defaultBatchInterval = 100 * time.Millisecond
+
+var (
+	registryQueueDepth     *metrics.Metric
+	registryCommitDuration *metrics.Metric
+	registryWriteErrors    *metrics.Metric
+	registryObjects        *metrics.Metric
+	registryStatusObjects  *metrics.Metric
+	registryPayloadBytes   *metrics.Metric
+)
+
 // Ensure the interfaces are implemented correctly.
 var (
 	_ modules.Instance = &Registry{}
@@ -58,10 +90,32 @@ func (r *Registry) Exports() modules.Exports {
 // in the specified file. If repository instance for the file was previously created, then
 // Open will return the existing instance of repository, because bolt database allows only
 // one write connection at a time.
-func (r *Registry) Open(dbFilePath string) *ObjRegistry {
+//
+// The optional params is a dictionary with `batch_size` key, which enables
+// write-behind mode: writes are queued and committed in batches of this size
+// or every `batch_interval` (100ms by default). Params of the first call for
+// the file are used. The `stats_interval` key enables periodic reporting of
+// registry stats as metrics.
+func (r *Registry) Open(dbFilePath string, params map[string]string) *VUObjRegistry {
+	batchSize, batchInterval, err := parseBatchParams(params)
+	if err != nil {
+		panic(err)
+	}
+	statsInterval, err := parseStatsInterval(params)
+	if err != nil {
+		panic(err)
+	}
+
 	r.root.mu.Lock()
 	defer r.root.mu.Unlock()
-	return r.open(dbFilePath)
+	registry := r.open(dbFilePath)
+	if batchSize > 0 && registry.batch == nil {
+		registry.batch = newBatchWriter(registry.boltDB, batchSize, batchInterval)
+	}
+	if statsInterval > 0 && registry.stats == nil {
+		registry.startStats(statsInterval)
+	}
+	return &VUObjRegistry{ObjRegistry: registry, vu: r.vu}
 }
 
 // Implementation of Open without mutex lock, so that it can be re-used in other methods.
@@ -70,33 +124,183 @@ func (r *Registry) open(dbFilePath string) *ObjRegistry {
 	if registry == nil {
 		registry = NewObjRegistry(r.vu.Context(), dbFilePath)
 		r.root.registries[dbFilePath] = registry
+
+		// register metrics
+		metricsRegistry := metrics.NewRegistry()
+		registryQueueDepth, _ = metricsRegistry.NewMetric("neofs_registry_queue_depth", metrics.Gauge)
+		registryCommitDuration, _ = metricsRegistry.NewMetric("neofs_registry_commit_duration", metrics.Trend, metrics.Time)
+		registryWriteErrors, _ = metricsRegistry.NewMetric("neofs_registry_write_errors", metrics.Counter)
+		registryObjects, _ = metricsRegistry.NewMetric("neofs_registry_objects", metrics.Gauge)
+		registryStatusObjects, _ = metricsRegistry.NewMetric("neofs_registry_status_objects", metrics.Gauge)
+		registryPayloadBytes, _ = metricsRegistry.NewMetric("neofs_registry_payload_bytes", metrics.Gauge, metrics.Data)
 	}
 	return registry
 }
 
-func (r *Registry) GetSelector(dbFilePath string, name string, cacheSize int, filter map[string]string) *ObjSelector {
+// GetSelector returns selector of objects from the registry file shared by
+// all VUs by name. The optional params is a dictionary with `mode` key
+// (`forward`, `loop`, `recent`, `random` or `zipf`) and `zipf_s` key with
+// Zipf exponent (1.1 by default).
+func (r *Registry) GetSelector(dbFilePath string, name string, cacheSize int, filter map[string]string, params map[string]string) *VUObjSelector {
 	objFilter, err := parseFilter(filter)
 	if err != nil {
 		panic(err)
 	}
+	selectorParams, err := parseSelectorParams(params)
+	if err != nil {
+		panic(err)
+	}
 
 	r.root.mu.Lock()
 	defer r.root.mu.Unlock()
 
+	registry := r.open(dbFilePath)
 	selector := r.root.selectors[name]
 	if selector == nil {
-		registry := r.open(dbFilePath)
-		selector = NewObjSelector(registry, cacheSize, objFilter)
+		selector = NewObjSelector(registry, cacheSize, objFilter, selectorParams)
 		r.root.selectors[name] = selector
 	} else if !reflect.DeepEqual(selector.filter, objFilter) {
 		panic(fmt.Sprintf("selector %s already has been created with a different filter", name))
+	} else if selector.params != *selectorParams {
+		panic(fmt.Sprintf("selector %s already has been created with different params", name))
+	}
+	return &VUObjSelector{ObjSelector: selector, vu: r.vu, registry: registry}
+}
+
+func parseSelectorParams(params map[string]string) (*SelectorParams, error) {
+	p := &SelectorParams{Mode: params["mode"]}
+	switch p.Mode {
+	case "":
+		p.Mode = selectorModeForward
+	case selectorModeForward, selectorModeLoop, selectorModeRecent, selectorModeRandom:
+	case selectorModeZipf:
+		p.ZipfS = 1.1
+		if s, ok := params["zipf_s"]; ok {
+			v, err := strconv.ParseFloat(s, 64)
+			if err != nil || v <= 1 {
+				return nil, fmt.Errorf("invalid value for 'zipf_s': '%s'", s)
+			}
+			p.ZipfS = v
+		}
+	default:
+		return nil, fmt.Errorf("unknown selector mode: '%s'", p.Mode)
+	}
+	return p, nil
+}
+
+func parseBatchParams(params map[string]string) (int, time.Duration, error) {
+	var (
+		size     int
+		interval = defaultBatchInterval
+		err      error
+	)
+	if s, ok := params["batch_size"]; ok {
+		if size, err = strconv.Atoi(s); err != nil || size < 0 {
+			return 0, 0, fmt.Errorf("invalid value for 'batch_size': '%s'", s)
+		}
+	}
+	if s, ok := params["batch_interval"]; ok {
+		if interval, err = time.ParseDuration(s); err != nil || interval <= 0 {
+			return 0, 0, fmt.Errorf("invalid value for 'batch_interval': '%s'", s)
+		}
+	}
+	return size, interval, nil
+}
+
+func (r *VUObjRegistry) AddObject(cid, oid, s3Bucket, s3Key, payloadHash string, payloadSize int64) error {
+	defer r.report()
+	return r.ObjRegistry.AddObject(cid, oid, s3Bucket, s3Key, payloadHash, payloadSize)
+}
+
+func (r *VUObjRegistry) AddSeededObject(cid, oid, s3Bucket, s3Key, payloadSeed string, payloadSize int64, payloadParams map[string]string) error {
+	defer r.report()
+	return r.ObjRegistry.AddSeededObject(cid, oid, s3Bucket, s3Key, payloadSeed, payloadSize, payloadParams)
+}
+
+func (r *VUObjRegistry) SetObjectStatus(id uint64, newStatus string) error {
+	defer r.report()
+	return r.ObjRegistry.SetObjectStatus(id, newStatus)
+}
+
+func (r *VUObjRegistry) AddAttempt(id uint64, operation, errMsg string) error {
+	defer r.report()
+	return r.ObjRegistry.AddAttempt(id, operation, errMsg)
+}
+
+func (r *VUObjRegistry) DeleteObject(id uint64) error {
+	defer r.report()
+	return r.ObjRegistry.DeleteObject(id)
+}
+
+// report reports queue depth and results of commits made since the previous
+// report in write-behind mode.
+func (r *VUObjRegistry) report() {
+	reportRegistry(r.vu, r.ObjRegistry)
+}
+
+// NextObject returns the next object of the selector and reports registry
+// metrics.
+func (s *VUObjSelector) NextObject() *ObjectInfo {
+	defer reportRegistry(s.vu, s.registry)
+	return s.ObjSelector.NextObject()
+}
+
+// reportRegistry reports stats computed since the last report and results of
+// commits in write-behind mode on behalf of the VU.
+func reportRegistry(vu modules.VU, registry *ObjRegistry) {
+	if vu.State() == nil {
+		return
+	}
+
+	if s := registry.takeStats(); s != nil {
+		stats.Report(vu, registryObjects, float64(s.Objects))
+		// All statuses are reported, so that gauges drop to zero when there
+		// are no objects in the status anymore
+		for status := range statusTransitions {
+			stats.ReportWithTags(vu, registryStatusObjects, float64(s.Statuses[status]), map[string]string{"status": status})
+		}
+		stats.Report(vu, registryPayloadBytes, float64(s.PayloadBytes))
+	}
+
+	batch := registry.batch
+	if batch == nil {
+		return
+	}
+
+	stats.Report(vu, registryQueueDepth, float64(batch.queueDepth()))
+	for {
+		select {
+		case res := <-batch.commits:
+			stats.Report(vu, registryCommitDuration, metrics.D(res.duration))
+			if len(res.errors) > 0 {
+				stats.Report(vu, registryWriteErrors, float64(len(res.errors)))
+			}
+			for _, err := range res.errors {
+				vu.State().Logger.WithError(err).Warn("registry write failed")
+			}
+		default:
+			return
+		}
+	}
+}
+
+func parseStatsInterval(params map[string]string) (time.Duration, error) {
+	s, ok := params["stats_interval"]
+	if !ok {
+		return 0, nil
+	}
+	interval, err := time.ParseDuration(s)
+	if err != nil || interval < 0 {
+		return 0, fmt.Errorf("invalid value for 'stats_interval': '%s'", s)
 	}
-	return selector
+	return interval, nil
 }
 
 func parseFilter(filter map[string]string) (*ObjFilter, error) {
 	objFilter := ObjFilter{}
 	objFilter.Status = filter["status"]
+	objFilter.Container = filter["container"]
+	objFilter.Bucket = filter["bucket"]
 
 	if ageStr := filter["age"]; ageStr != "" {
 		age, err := strconv.ParseInt(ageStr, 10, 64)
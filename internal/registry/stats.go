@@ -0,0 +1,136 @@
+package registry
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// RegistryStats is a summary of registry contents.
+type RegistryStats struct {
+	Objects      uint64            // Total number of objects
+	Statuses     map[string]uint64 // Number of objects per status
+	Containers   map[string]uint64 // Number of gRPC/HTTP objects per container
+	Buckets      map[string]uint64 // Number of S3 objects per bucket
+	Ages         []AgeCount        // Histogram of object ages from the youngest
+	PayloadBytes int64             // Total payload size of objects with known size
+}
+
+// AgeCount is a bucket of object age histogram.
+type AgeCount struct {
+	Age   string // Age range, e.g. "<1h"
+	Count uint64
+}
+
+// ageBuckets are upper bounds of age histogram buckets, objects older than
+// the last bound are counted in an extra bucket.
+var ageBuckets = []struct {
+	label string
+	bound time.Duration
+}{
+	{"<1m", time.Minute},
+	{"<10m", 10 * time.Minute},
+	{"<1h", time.Hour},
+	{"<1d", 24 * time.Hour},
+	{"<7d", 7 * 24 * time.Hour},
+}
+
+const oldestAgeLabel = ">=7d"
+
+// statsReporter computes registry stats periodically in background, so that
+// VUs can report them as metrics without scanning the registry.
+type statsReporter struct {
+	latest atomic.Pointer[RegistryStats]
+	done   chan struct{}
+}
+
+// Stats returns counts of objects per status and per container/bucket,
+// histogram of object ages and total payload size. Everything is read from
+// counters kept along with indexes, so stats don't depend on the number of
+// objects, ages are calculated with minute precision. Payload size is known
+// only for objects added with it.
+func (o *ObjRegistry) Stats() (*RegistryStats, error) {
+	o.Flush()
+	return o.readStats()
+}
+
+func (o *ObjRegistry) readStats() (*RegistryStats, error) {
+	res := &RegistryStats{
+		Containers: make(map[string]uint64),
+		Buckets:    make(map[string]uint64),
+	}
+	err := o.boltDB.View(func(tx *bbolt.Tx) error {
+		res.Statuses = readCounters(tx, statusIndexName)
+		for _, n := range res.Statuses {
+			res.Objects += n
+		}
+		for location, n := range readCounters(tx, locationIndexName) {
+			if bucket, ok := strings.CutPrefix(location, "s3/"); ok {
+				res.Buckets[bucket] = n
+			} else if cid, ok := strings.CutPrefix(location, "cid/"); ok {
+				res.Containers[cid] = n
+			}
+		}
+		res.PayloadBytes = int64(readCounter(tx, payloadCounterName, ""))
+
+		ages := make([]uint64, len(ageBuckets)+1)
+		now := time.Now()
+		for minute, n := range readCounters(tx, createdCounterName) {
+			m, err := strconv.ParseInt(minute, 10, 64)
+			if err != nil {
+				continue
+			}
+			age := now.Sub(time.Unix(m*60, 0))
+			i := 0
+			for i < len(ageBuckets) && age >= ageBuckets[i].bound {
+				i++
+			}
+			ages[i] += n
+		}
+
+		for i, n := range ages {
+			label := oldestAgeLabel
+			if i < len(ageBuckets) {
+				label = ageBuckets[i].label
+			}
+			res.Ages = append(res.Ages, AgeCount{Age: label, Count: n})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// startStats starts computing stats every interval until the registry is
+// closed.
+func (o *ObjRegistry) startStats(interval time.Duration) {
+	o.stats = &statsReporter{done: make(chan struct{})}
+	go func() {
+		defer close(o.stats.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-o.ctx.Done():
+				return
+			case <-ticker.C:
+				if s, err := o.readStats(); err == nil {
+					o.stats.latest.Store(s)
+				}
+			}
+		}
+	}()
+}
+
+// takeStats returns stats computed since the last call or nil.
+func (o *ObjRegistry) takeStats() *RegistryStats {
+	if o.stats == nil {
+		return nil
+	}
+	return o.stats.latest.Swap(nil)
+}
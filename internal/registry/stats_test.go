@@ -0,0 +1,68 @@
+package registry
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	r := newTestRegistry(t)
+
+	require.NoError(t, r.AddObject("c1", "o1", "", "", "h", 512))
+	require.NoError(t, r.AddSeededObject("c1", "o2", "", "", "seed", 1024, nil))
+	require.NoError(t, r.SetObjectStatus(1, statusVerified))
+
+	// Objects created 2 hours ago
+	old := time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+	_, err := r.Import(strings.NewReader(`{"s3_bucket": "b1", "s3_key": "k1", "payload_size": 2048, "created_at": "`+old+`"}`), FormatJSONL)
+	require.NoError(t, err)
+
+	s, err := r.Stats()
+	require.NoError(t, err)
+	require.EqualValues(t, 3, s.Objects)
+	require.Equal(t, map[string]uint64{statusCreated: 2, statusVerified: 1}, s.Statuses)
+	require.Equal(t, map[string]uint64{"c1": 2}, s.Containers)
+	require.Equal(t, map[string]uint64{"b1": 1}, s.Buckets)
+	require.EqualValues(t, 3584, s.PayloadBytes)
+	require.Equal(t, []AgeCount{
+		{Age: "<1m", Count: 2},
+		{Age: "<10m"},
+		{Age: "<1h"},
+		{Age: "<1d", Count: 1},
+		{Age: "<7d"},
+		{Age: ">=7d"},
+	}, s.Ages)
+
+	// Counters are updated on removal
+	require.NoError(t, r.DeleteObject(3))
+	s, err = r.Stats()
+	require.NoError(t, err)
+	require.EqualValues(t, 2, s.Objects)
+	require.EqualValues(t, 1536, s.PayloadBytes)
+	require.EqualValues(t, 0, s.Ages[3].Count)
+}
+
+func TestStatsEmpty(t *testing.T) {
+	r := newTestRegistry(t)
+
+	s, err := r.Stats()
+	require.NoError(t, err)
+	require.Zero(t, s.Objects)
+	require.Empty(t, s.Statuses)
+	require.Len(t, s.Ages, len(ageBuckets)+1)
+}
+
+func TestStatsPeriodic(t *testing.T) {
+	r := newTestRegistry(t)
+	require.NoError(t, r.AddObject("c1", "o1", "", "", "h", 0))
+
+	require.Nil(t, r.takeStats())
+	r.startStats(10 * time.Millisecond)
+	require.Eventually(t, func() bool {
+		s := r.takeStats()
+		return s != nil && s.Objects == 1
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,78 @@
+package registry
+
+import (
+	"fmt"
+	"time"
+)
+
+// Object statuses in the registry.
+const (
+	// Indicates that an object was created, but its data wasn't verified yet.
+	statusCreated = "created"
+	// Indicates that object data was verified successfully.
+	statusVerified = "verified"
+	// Indicates that object doesn't exist or its data is corrupted.
+	statusInvalid = "invalid"
+	// Indicates that object can't be verified with available protocols.
+	statusSkipped = "skipped"
+	// Indicates that object was deleted from the storage.
+	statusDeleted = "deleted"
+	// Indicates that object deletion failed, it can be retried.
+	statusDeleteFailed = "delete_failed"
+	// Indicates that object is expired and shouldn't be accessed anymore.
+	statusExpired = "expired"
+)
+
+// maxHistory is the maximum number of status changes and attempts kept per
+// object, older records are dropped.
+const maxHistory = 32
+
+// statusTransitions lists statuses every status can be changed to. Deleted is
+// a final status.
+var statusTransitions = map[string][]string{
+	statusCreated:      {statusVerified, statusInvalid, statusSkipped, statusDeleted, statusDeleteFailed, statusExpired},
+	statusVerified:     {statusVerified, statusInvalid, statusDeleted, statusDeleteFailed, statusExpired},
+	statusInvalid:      {statusVerified, statusInvalid, statusDeleted, statusDeleteFailed, statusExpired},
+	statusSkipped:      {statusVerified, statusInvalid, statusSkipped, statusDeleted, statusDeleteFailed, statusExpired},
+	statusDeleteFailed: {statusDeleted, statusDeleteFailed, statusExpired},
+	statusExpired:      {statusDeleted, statusDeleteFailed},
+	statusDeleted:      nil,
+}
+
+// StatusChange is a record of object status change.
+type StatusChange struct {
+	Status string    // New status of the object
+	At     time.Time // UTC date&time of the change
+}
+
+// Attempt is a record of operation (e.g. verification or deletion) performed
+// with the object.
+type Attempt struct {
+	Operation string    // Name of the operation
+	At        time.Time // UTC date&time of the attempt
+	Error     string    // Error of the failed attempt, empty on success
+}
+
+// checkTransition returns error if object status can't be changed from one
+// status to another.
+func checkTransition(from, to string) error {
+	if _, ok := statusTransitions[to]; !ok {
+		return fmt.Errorf("unknown status: '%s'", to)
+	}
+	for _, s := range statusTransitions[from] {
+		if s == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid status transition from '%s' to '%s'", from, to)
+}
+
+// appendLimited appends record to the list dropping the oldest records above
+// maxHistory.
+func appendLimited[T any](list []T, record T) []T {
+	list = append(list, record)
+	if len(list) > maxHistory {
+		list = append(list[:0], list[len(list)-maxHistory:]...)
+	}
+	return list
+}
@@ -0,0 +1,63 @@
+package registry
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRegistry(t testing.TB) *ObjRegistry {
+	r := NewObjRegistry(context.Background(), filepath.Join(t.TempDir(), "registry.bolt"))
+	t.Cleanup(func() { _ = r.Close() })
+	return r
+}
+
+func TestCheckTransition(t *testing.T) {
+	require.NoError(t, checkTransition(statusCreated, statusVerified))
+	require.NoError(t, checkTransition(statusVerified, statusDeleted))
+	require.NoError(t, checkTransition(statusDeleteFailed, statusDeleted))
+
+	require.Error(t, checkTransition(statusCreated, "unknown"))
+	require.Error(t, checkTransition(statusDeleted, statusCreated))
+	require.Error(t, checkTransition(statusVerified, statusCreated))
+	require.Error(t, checkTransition(statusExpired, statusVerified))
+}
+
+func TestObjectStatus(t *testing.T) {
+	r := newTestRegistry(t)
+	require.NoError(t, r.AddObject("cid", "oid", "", "", "hash", 0))
+
+	obj, err := r.GetObject(1)
+	require.NoError(t, err)
+	require.Equal(t, statusCreated, obj.Status)
+	require.Equal(t, []StatusChange{{Status: statusCreated, At: obj.CreatedAt}}, obj.History)
+
+	require.NoError(t, r.AddAttempt(1, "verify", "timeout"))
+	require.NoError(t, r.AddAttempt(1, "verify", ""))
+	require.NoError(t, r.SetObjectStatus(1, statusVerified))
+	require.Error(t, r.SetObjectStatus(1, statusCreated))
+	require.Error(t, r.SetObjectStatus(2, statusVerified))
+
+	obj, err = r.GetObject(1)
+	require.NoError(t, err)
+	require.Equal(t, statusVerified, obj.Status)
+	require.Len(t, obj.History, 2)
+	require.Equal(t, statusVerified, obj.History[1].Status)
+	require.Equal(t, obj.StatusChangedAt, obj.History[1].At)
+	require.Len(t, obj.Attempts, 2)
+	require.Equal(t, "timeout", obj.Attempts[0].Error)
+	require.Empty(t, obj.Attempts[1].Error)
+
+	for range maxHistory {
+		require.NoError(t, r.SetObjectStatus(1, statusVerified))
+	}
+	obj, err = r.GetObject(1)
+	require.NoError(t, err)
+	require.Len(t, obj.History, maxHistory)
+
+	obj, err = r.GetObject(2)
+	require.NoError(t, err)
+	require.Nil(t, obj)
+}
@@ -0,0 +1,345 @@
+package s3
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/nspcc-dev/xk6-neofs/internal/stats"
+	"go.k6.io/k6/metrics"
+)
+
+type (
+	DeleteBucketResponse struct {
+		Success bool
+		Error   string
+	}
+
+	ListBucketsResponse struct {
+		Success bool
+		Buckets []string
+		Error   string
+	}
+
+	PutBucketPolicyResponse struct {
+		Success bool
+		Error   string
+	}
+
+	GetBucketPolicyResponse struct {
+		Success bool
+		Policy  string
+		Error   string
+	}
+
+	PutBucketCorsResponse struct {
+		Success bool
+		Error   string
+	}
+
+	GetBucketCorsResponse struct {
+		Success bool
+		Cors    string
+		Error   string
+	}
+
+	PutBucketACLResponse struct {
+		Success bool
+		Error   string
+	}
+
+	GetBucketACLResponse struct {
+		Success bool
+		Owner   string
+		Grants  []ACLGrant
+		Error   string
+	}
+
+	// ACLGrant is a single permission of the bucket ACL. Grantee is either
+	// canonical user ID, e-mail or group URI depending on Type.
+	ACLGrant struct {
+		Grantee    string
+		Type       string
+		Permission string
+	}
+)
+
+type (
+	// corsConfiguration is a CORS configuration in the format used by AWS CLI.
+	corsConfiguration struct {
+		CORSRules []corsRule
+	}
+
+	// corsRule is types.CORSRule without unset fields in JSON.
+	corsRule struct {
+		ID             *string  `json:",omitempty"`
+		AllowedHeaders []string `json:",omitempty"`
+		AllowedMethods []string
+		AllowedOrigins []string
+		ExposeHeaders  []string `json:",omitempty"`
+		MaxAgeSeconds  *int32   `json:",omitempty"`
+	}
+)
+
+// deleteBatchSize is the maximum number of keys in a single multi-object
+// delete request.
+const deleteBatchSize = 1000
+
+// DeleteBucket removes the bucket. If `empty` parameter is true, all object
+// versions and delete markers are removed from the bucket first.
+func (c *Client) DeleteBucket(bucket string, params map[string]string) DeleteBucketResponse {
+	stats.Report(c.vu, deleteBucketTotal, 1)
+
+	var err error
+	var empty bool
+	if emptyStr, ok := params["empty"]; ok {
+		if empty, err = strconv.ParseBool(emptyStr); err != nil {
+			stats.Report(c.vu, deleteBucketFails, 1)
+			return DeleteBucketResponse{Success: false, Error: "invalid empty params"}
+		}
+	}
+
+	start := time.Now()
+	if empty {
+		if err = c.emptyBucket(bucket); err != nil {
+			stats.Report(c.vu, deleteBucketFails, 1)
+			return DeleteBucketResponse{Success: false, Error: err.Error()}
+		}
+	}
+
+	_, err = c.cli.DeleteBucket(c.vu.Context(), &s3.DeleteBucketInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		stats.Report(c.vu, deleteBucketFails, 1)
+		return DeleteBucketResponse{Success: false, Error: err.Error()}
+	}
+
+	stats.Report(c.vu, deleteBucketDuration, metrics.D(time.Since(start)))
+	return DeleteBucketResponse{Success: true}
+}
+
+func (c *Client) emptyBucket(bucket string) error {
+	ctx := c.vu.Context()
+	paginator := s3.NewListObjectVersionsPaginator(c.cli, &s3.ListObjectVersionsInput{
+		Bucket:  aws.String(bucket),
+		MaxKeys: aws.Int32(deleteBatchSize),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("list object versions: %w", err)
+		}
+
+		objects := make([]types.ObjectIdentifier, 0, len(page.Versions)+len(page.DeleteMarkers))
+		for _, v := range page.Versions {
+			objects = append(objects, types.ObjectIdentifier{Key: v.Key, VersionId: v.VersionId})
+		}
+		for _, m := range page.DeleteMarkers {
+			objects = append(objects, types.ObjectIdentifier{Key: m.Key, VersionId: m.VersionId})
+		}
+
+		for len(objects) > 0 {
+			batch := objects[:min(len(objects), deleteBatchSize)]
+			objects = objects[len(batch):]
+
+			res, err := c.cli.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+				Bucket: aws.String(bucket),
+				Delete: &types.Delete{Objects: batch, Quiet: aws.Bool(true)},
+			})
+			if err != nil {
+				return fmt.Errorf("delete objects: %w", err)
+			}
+			if len(res.Errors) > 0 {
+				e := res.Errors[0]
+				return fmt.Errorf("delete object '%s': %s", aws.ToString(e.Key), aws.ToString(e.Message))
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Client) ListBuckets() ListBucketsResponse {
+	stats.Report(c.vu, listBucketsTotal, 1)
+	start := time.Now()
+
+	var buckets []string
+	paginator := s3.NewListBucketsPaginator(c.cli, &s3.ListBucketsInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(c.vu.Context())
+		if err != nil {
+			stats.Report(c.vu, listBucketsFails, 1)
+			return ListBucketsResponse{Success: false, Error: err.Error()}
+		}
+		for _, b := range page.Buckets {
+			buckets = append(buckets, aws.ToString(b.Name))
+		}
+	}
+
+	stats.Report(c.vu, listBucketsDuration, metrics.D(time.Since(start)))
+	return ListBucketsResponse{Success: true, Buckets: buckets}
+}
+
+// PutBucketPolicy sets bucket policy, the policy is a JSON document.
+func (c *Client) PutBucketPolicy(bucket, policy string) PutBucketPolicyResponse {
+	stats.Report(c.vu, putBucketPolicyTotal, 1)
+	start := time.Now()
+
+	_, err := c.cli.PutBucketPolicy(c.vu.Context(), &s3.PutBucketPolicyInput{
+		Bucket: aws.String(bucket),
+		Policy: aws.String(policy),
+	})
+	if err != nil {
+		stats.Report(c.vu, putBucketPolicyFails, 1)
+		return PutBucketPolicyResponse{Success: false, Error: err.Error()}
+	}
+
+	stats.Report(c.vu, putBucketPolicyDuration, metrics.D(time.Since(start)))
+	return PutBucketPolicyResponse{Success: true}
+}
+
+func (c *Client) GetBucketPolicy(bucket string) GetBucketPolicyResponse {
+	stats.Report(c.vu, getBucketPolicyTotal, 1)
+	start := time.Now()
+
+	res, err := c.cli.GetBucketPolicy(c.vu.Context(), &s3.GetBucketPolicyInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		stats.Report(c.vu, getBucketPolicyFails, 1)
+		return GetBucketPolicyResponse{Success: false, Error: err.Error()}
+	}
+
+	stats.Report(c.vu, getBucketPolicyDuration, metrics.D(time.Since(start)))
+	return GetBucketPolicyResponse{Success: true, Policy: aws.ToString(res.Policy)}
+}
+
+// PutBucketCors sets bucket CORS configuration. The cors is a JSON document
+// in AWS CLI format, e.g. `{"CORSRules":[{"AllowedMethods":["GET"],"AllowedOrigins":["*"]}]}`.
+func (c *Client) PutBucketCors(bucket, cors string) PutBucketCorsResponse {
+	stats.Report(c.vu, putBucketCorsTotal, 1)
+
+	var cfg corsConfiguration
+	if err := json.Unmarshal([]byte(cors), &cfg); err != nil {
+		stats.Report(c.vu, putBucketCorsFails, 1)
+		return PutBucketCorsResponse{Success: false, Error: fmt.Sprintf("invalid cors configuration: %s", err)}
+	}
+
+	rules := make([]types.CORSRule, 0, len(cfg.CORSRules))
+	for _, r := range cfg.CORSRules {
+		rules = append(rules, types.CORSRule{
+			ID:             r.ID,
+			AllowedHeaders: r.AllowedHeaders,
+			AllowedMethods: r.AllowedMethods,
+			AllowedOrigins: r.AllowedOrigins,
+			ExposeHeaders:  r.ExposeHeaders,
+			MaxAgeSeconds:  r.MaxAgeSeconds,
+		})
+	}
+
+	start := time.Now()
+	_, err := c.cli.PutBucketCors(c.vu.Context(), &s3.PutBucketCorsInput{
+		Bucket:            aws.String(bucket),
+		CORSConfiguration: &types.CORSConfiguration{CORSRules: rules},
+	})
+	if err != nil {
+		stats.Report(c.vu, putBucketCorsFails, 1)
+		return PutBucketCorsResponse{Success: false, Error: err.Error()}
+	}
+
+	stats.Report(c.vu, putBucketCorsDuration, metrics.D(time.Since(start)))
+	return PutBucketCorsResponse{Success: true}
+}
+
+// GetBucketCors returns bucket CORS configuration as a JSON document in the
+// same format PutBucketCors accepts.
+func (c *Client) GetBucketCors(bucket string) GetBucketCorsResponse {
+	stats.Report(c.vu, getBucketCorsTotal, 1)
+	start := time.Now()
+
+	res, err := c.cli.GetBucketCors(c.vu.Context(), &s3.GetBucketCorsInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		stats.Report(c.vu, getBucketCorsFails, 1)
+		return GetBucketCorsResponse{Success: false, Error: err.Error()}
+	}
+
+	stats.Report(c.vu, getBucketCorsDuration, metrics.D(time.Since(start)))
+
+	cfg := corsConfiguration{CORSRules: make([]corsRule, 0, len(res.CORSRules))}
+	for _, r := range res.CORSRules {
+		cfg.CORSRules = append(cfg.CORSRules, corsRule{
+			ID:             r.ID,
+			AllowedHeaders: r.AllowedHeaders,
+			AllowedMethods: r.AllowedMethods,
+			AllowedOrigins: r.AllowedOrigins,
+			ExposeHeaders:  r.ExposeHeaders,
+			MaxAgeSeconds:  r.MaxAgeSeconds,
+		})
+	}
+	cors, err := json.Marshal(cfg)
+	if err != nil {
+		return GetBucketCorsResponse{Success: false, Error: err.Error()}
+	}
+	return GetBucketCorsResponse{Success: true, Cors: string(cors)}
+}
+
+// PutBucketACL sets canned ACL (e.g. `private` or `public-read`) of the bucket.
+func (c *Client) PutBucketACL(bucket, acl string) PutBucketACLResponse {
+	stats.Report(c.vu, putBucketACLTotal, 1)
+	start := time.Now()
+
+	_, err := c.cli.PutBucketAcl(c.vu.Context(), &s3.PutBucketAclInput{
+		Bucket: aws.String(bucket),
+		ACL:    types.BucketCannedACL(acl),
+	})
+	if err != nil {
+		stats.Report(c.vu, putBucketACLFails, 1)
+		return PutBucketACLResponse{Success: false, Error: err.Error()}
+	}
+
+	stats.Report(c.vu, putBucketACLDuration, metrics.D(time.Since(start)))
+	return PutBucketACLResponse{Success: true}
+}
+
+func (c *Client) GetBucketACL(bucket string) GetBucketACLResponse {
+	stats.Report(c.vu, getBucketACLTotal, 1)
+	start := time.Now()
+
+	res, err := c.cli.GetBucketAcl(c.vu.Context(), &s3.GetBucketAclInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		stats.Report(c.vu, getBucketACLFails, 1)
+		return GetBucketACLResponse{Success: false, Error: err.Error()}
+	}
+
+	stats.Report(c.vu, getBucketACLDuration, metrics.D(time.Since(start)))
+
+	resp := GetBucketACLResponse{Success: true, Grants: make([]ACLGrant, 0, len(res.Grants))}
+	if res.Owner != nil {
+		resp.Owner = aws.ToString(res.Owner.ID)
+	}
+	for _, g := range res.Grants {
+		grant := ACLGrant{Permission: string(g.Permission)}
+		if g.Grantee != nil {
+			grant.Type = string(g.Grantee.Type)
+			switch g.Grantee.Type {
+			case types.TypeGroup:
+				grant.Grantee = aws.ToString(g.Grantee.URI)
+			case types.TypeAmazonCustomerByEmail:
+				grant.Grantee = aws.ToString(g.Grantee.EmailAddress)
+			default:
+				grant.Grantee = aws.ToString(g.Grantee.ID)
+			}
+		}
+		resp.Grants = append(resp.Grants, grant)
+	}
+	return resp
+}
@@ -0,0 +1,164 @@
+package s3
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteBucketEmpty(t *testing.T) {
+	// The first page has more entries than a single delete request takes.
+	const firstPage = deleteBatchSize + 1
+
+	var failDelete atomic.Bool
+	srv, requests := newRecordingServer(t, func(r *http.Request) string {
+		q := r.URL.Query()
+		switch {
+		case q.Has("versions") && q.Get("key-marker") == "":
+			var b strings.Builder
+			b.WriteString("<ListVersionsResult><IsTruncated>true</IsTruncated>" +
+				"<NextKeyMarker>next</NextKeyMarker><NextVersionIdMarker>v</NextVersionIdMarker>")
+			for i := range firstPage - 1 {
+				fmt.Fprintf(&b, "<Version><Key>key%d</Key><VersionId>v%d</VersionId></Version>", i, i)
+			}
+			b.WriteString("<DeleteMarker><Key>marker</Key><VersionId>m</VersionId></DeleteMarker>")
+			b.WriteString("</ListVersionsResult>")
+			return b.String()
+		case q.Has("versions"):
+			return "<ListVersionsResult><IsTruncated>false</IsTruncated>" +
+				"<Version><Key>last</Key><VersionId>l</VersionId></Version></ListVersionsResult>"
+		case q.Has("delete") && failDelete.Load():
+			return "<DeleteResult><Error><Key>last</Key><Code>AccessDenied</Code>" +
+				"<Message>Access Denied</Message></Error></DeleteResult>"
+		case q.Has("delete"):
+			return "<DeleteResult></DeleteResult>"
+		}
+		return ""
+	})
+	cli, _ := newTestClient(t, srv.URL, nil)
+
+	type deleteRequest struct {
+		Quiet   bool
+		Objects []struct {
+			Key       string
+			VersionID string `xml:"VersionId"`
+		} `xml:"Object"`
+	}
+
+	resp := cli.DeleteBucket("bucket", map[string]string{"empty": "true"})
+	require.True(t, resp.Success, resp.Error)
+
+	var (
+		deleted  = make(map[string]string)
+		batches  []int
+		listings []string
+	)
+	for range 6 {
+		req := <-requests
+		switch {
+		case req.query.Has("versions"):
+			require.Equal(t, "1000", req.query.Get("max-keys"))
+			listings = append(listings, req.query.Get("key-marker")+"/"+req.query.Get("version-id-marker"))
+		case req.query.Has("delete"):
+			var del deleteRequest
+			require.NoError(t, xml.Unmarshal(req.body, &del))
+			require.True(t, del.Quiet)
+			batches = append(batches, len(del.Objects))
+			for _, o := range del.Objects {
+				deleted[o.Key] = o.VersionID
+			}
+		default:
+			require.Equal(t, http.MethodDelete, req.method)
+			require.Equal(t, "/bucket", req.path)
+		}
+	}
+	require.Equal(t, []string{"/", "next/v"}, listings)
+	require.Equal(t, []int{deleteBatchSize, 1, 1}, batches)
+	require.Len(t, deleted, firstPage+1)
+	require.Equal(t, "v42", deleted["key42"])
+	require.Equal(t, "m", deleted["marker"])
+	require.Equal(t, "l", deleted["last"])
+
+	// Bucket isn't removed if some object can't be deleted.
+	failDelete.Store(true)
+	resp = cli.DeleteBucket("bucket", map[string]string{"empty": "true"})
+	require.False(t, resp.Success)
+	require.Contains(t, resp.Error, "Access Denied")
+	for len(requests) > 0 {
+		require.NotEqual(t, http.MethodDelete, (<-requests).method)
+	}
+
+	resp = cli.DeleteBucket("bucket", map[string]string{"empty": "yes"})
+	require.False(t, resp.Success)
+	require.Empty(t, requests)
+}
+
+func TestBucketCors(t *testing.T) {
+	var stored atomic.Pointer[[]byte]
+	srv, requests := newRecordingServer(t, func(r *http.Request) string {
+		if r.Method == http.MethodGet {
+			return string(*stored.Load())
+		}
+		return ""
+	})
+	cli, _ := newTestClient(t, srv.URL, nil)
+
+	for _, cors := range []string{
+		`{"CORSRules":[{"AllowedMethods":["GET"],"AllowedOrigins":["*"]}]}`,
+		`{"CORSRules":[{"ID":"rule","AllowedHeaders":["*"],"AllowedMethods":["GET","PUT"],` +
+			`"AllowedOrigins":["https://example.com"],"ExposeHeaders":["ETag"],"MaxAgeSeconds":600}]}`,
+	} {
+		put := cli.PutBucketCors("bucket", cors)
+		require.True(t, put.Success, put.Error)
+		// Configuration is returned in the same XML it's sent.
+		req := <-requests
+		require.True(t, req.query.Has("cors"))
+		stored.Store(&req.body)
+
+		get := cli.GetBucketCors("bucket")
+		require.True(t, get.Success, get.Error)
+		require.JSONEq(t, cors, get.Cors)
+		<-requests
+	}
+
+	put := cli.PutBucketCors("bucket", "{")
+	require.False(t, put.Success)
+	require.Contains(t, put.Error, "invalid cors configuration")
+}
+
+func TestBucketACL(t *testing.T) {
+	srv, requests := newRecordingServer(t, func(r *http.Request) string {
+		if r.Method != http.MethodGet {
+			return ""
+		}
+		return `<AccessControlPolicy><Owner><ID>owner</ID></Owner><AccessControlList>` +
+			`<Grant><Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="CanonicalUser">` +
+			`<ID>user</ID></Grantee><Permission>FULL_CONTROL</Permission></Grant>` +
+			`<Grant><Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="Group">` +
+			`<URI>http://acs.amazonaws.com/groups/global/AllUsers</URI></Grantee><Permission>READ</Permission></Grant>` +
+			`<Grant><Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="AmazonCustomerByEmail">` +
+			`<EmailAddress>user@example.com</EmailAddress></Grantee><Permission>WRITE</Permission></Grant>` +
+			`</AccessControlList></AccessControlPolicy>`
+	})
+	cli, _ := newTestClient(t, srv.URL, nil)
+
+	put := cli.PutBucketACL("bucket", "public-read")
+	require.True(t, put.Success, put.Error)
+	req := <-requests
+	require.True(t, req.query.Has("acl"))
+	require.Equal(t, "public-read", req.header.Get("X-Amz-Acl"))
+
+	get := cli.GetBucketACL("bucket")
+	require.True(t, get.Success, get.Error)
+	require.Equal(t, "owner", get.Owner)
+	require.Equal(t, []ACLGrant{
+		{Grantee: "user", Type: "CanonicalUser", Permission: "FULL_CONTROL"},
+		{Grantee: "http://acs.amazonaws.com/groups/global/AllUsers", Type: "Group", Permission: "READ"},
+		{Grantee: "user@example.com", Type: "AmazonCustomerByEmail", Permission: "WRITE"},
+	}, get.Grants)
+}
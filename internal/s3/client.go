@@ -3,15 +3,27 @@ package s3
 import (
 	"bytes"
 	"context"
+	"crypto/md5"
+	"crypto/sha1"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/grafana/sobek"
+	"github.com/nspcc-dev/xk6-neofs/internal/datagen"
 	"github.com/nspcc-dev/xk6-neofs/internal/stats"
 	"go.k6.io/k6/js/modules"
 	"go.k6.io/k6/metrics"
@@ -19,13 +31,25 @@ import (
 
 type (
 	Client struct {
-		vu  modules.VU
-		cli *s3.Client
+		vu      modules.VU
+		cli     *s3.Client
+		httpCli *http.Client
+	}
+
+	// PutParams contains optional parameters of the Put operation.
+	PutParams struct {
+		Metadata          map[string]string
+		ContentType       string
+		StorageClass      string
+		ContentMD5        bool `js:"content_md5"`
+		ChecksumAlgorithm string
 	}
 
 	PutResponse struct {
-		Success bool
-		Error   string
+		Success   bool
+		VersionID string
+		ETag      string `js:"etag"`
+		Error     string
 	}
 
 	DeleteResponse struct {
@@ -47,20 +71,84 @@ type (
 		Success bool
 		Error   string
 	}
+
+	PutTaggingResponse struct {
+		Success bool
+		Error   string
+	}
+
+	GetTaggingResponse struct {
+		Success bool
+		Tags    map[string]string
+		Error   string
+	}
+
+	DeleteTaggingResponse struct {
+		Success bool
+		Error   string
+	}
+
+	CopyResponse struct {
+		Success   bool
+		VersionID string
+		Error     string
+	}
+
+	DeleteManyResponse struct {
+		Success bool
+		Results []DeleteManyResult
+		Error   string
+	}
+
+	// DeleteManyResult is a result of the single key removal in multi-object
+	// delete request.
+	DeleteManyResult struct {
+		Key     string
+		Success bool
+		Error   string
+	}
 )
 
-func (c *Client) Put(bucket, key string, payload sobek.ArrayBuffer) PutResponse {
-	rdr := bytes.NewReader(payload.Bytes())
+func (c *Client) Put(bucket, key string, payload sobek.Value, params PutParams) PutResponse {
+	data, err := datagen.PayloadBytes(payload)
+	if err != nil {
+		panic(err)
+	}
+	rdr := bytes.NewReader(data)
 	sz := rdr.Size()
 
 	stats.Report(c.vu, objPutTotal, 1)
 
+	input := &s3.PutObjectInput{
+		Bucket:   aws.String(bucket),
+		Key:      aws.String(key),
+		Body:     rdr,
+		Metadata: params.Metadata,
+	}
+	if params.ContentType != "" {
+		input.ContentType = aws.String(params.ContentType)
+	}
+	if params.StorageClass != "" {
+		input.StorageClass = types.StorageClass(params.StorageClass)
+	}
+
+	if params.ContentMD5 {
+		h := md5.Sum(data)
+		input.ContentMD5 = aws.String(base64.StdEncoding.EncodeToString(h[:]))
+	}
+
+	var checksum string
+	if params.ChecksumAlgorithm != "" {
+		input.ChecksumAlgorithm = types.ChecksumAlgorithm(strings.ToUpper(params.ChecksumAlgorithm))
+		if checksum, err = calcChecksum(input.ChecksumAlgorithm, data); err != nil {
+			stats.Report(c.vu, objPutFails, 1)
+			return PutResponse{Success: false, Error: err.Error()}
+		}
+		setChecksum(input, checksum)
+	}
+
 	start := time.Now()
-	_, err := c.cli.PutObject(c.vu.Context(), &s3.PutObjectInput{
-		Bucket: aws.String(bucket),
-		Key:    aws.String(key),
-		Body:   rdr,
-	})
+	res, err := c.cli.PutObject(c.vu.Context(), input)
 	if err != nil {
 		stats.Report(c.vu, objPutFails, 1)
 		return PutResponse{Success: false, Error: err.Error()}
@@ -68,16 +156,56 @@ func (c *Client) Put(bucket, key string, payload sobek.ArrayBuffer) PutResponse
 
 	stats.ReportDataSent(c.vu, float64(sz))
 	stats.Report(c.vu, objPutDuration, metrics.D(time.Since(start)))
-	return PutResponse{Success: true}
+
+	etag := strings.Trim(aws.ToString(res.ETag), `"`)
+	resp := PutResponse{Success: true, VersionID: aws.ToString(res.VersionId), ETag: etag}
+
+	// Multipart objects have ETag of a different format, so only plain MD5
+	// ETags are verified.
+	if isMD5ETag(etag) && etag != md5Hex(data) {
+		stats.Report(c.vu, objPutIntegrityFails, 1)
+		resp.Success = false
+		resp.Error = "etag mismatch"
+	}
+	if checksum != "" {
+		if actual := responseChecksum(res, input.ChecksumAlgorithm); actual != "" && actual != checksum {
+			stats.Report(c.vu, objPutIntegrityFails, 1)
+			resp.Success = false
+			resp.Error = "checksum mismatch"
+		}
+	}
+	return resp
+}
+
+// isMD5ETag checks whether ETag is a hex-encoded MD5 of the payload, ETags of
+// multipart uploads have '-N' suffix.
+func isMD5ETag(etag string) bool {
+	if len(etag) != 2*md5.Size {
+		return false
+	}
+	_, err := hex.DecodeString(etag)
+	return err == nil
+}
+
+func md5Hex(data []byte) string {
+	h := md5.Sum(data)
+	return hex.EncodeToString(h[:])
 }
 
 func (c *Client) Delete(bucket, key string) DeleteResponse {
+	return c.DeleteVersion(bucket, key, "")
+}
+
+// DeleteVersion removes the specified version of the object. Empty versionID
+// deletes the current version (or puts a delete marker in versioned bucket).
+func (c *Client) DeleteVersion(bucket, key, versionID string) DeleteResponse {
 	stats.Report(c.vu, objDeleteTotal, 1)
 	start := time.Now()
 
 	_, err := c.cli.DeleteObject(c.vu.Context(), &s3.DeleteObjectInput{
-		Bucket: aws.String(bucket),
-		Key:    aws.String(key),
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(key),
+		VersionId: optString(versionID),
 	})
 	if err != nil {
 		stats.Report(c.vu, objDeleteFails, 1)
@@ -89,11 +217,17 @@ func (c *Client) Delete(bucket, key string) DeleteResponse {
 }
 
 func (c *Client) Get(bucket, key string) GetResponse {
+	return c.GetVersion(bucket, key, "")
+}
+
+// GetVersion reads the specified version of the object. Empty versionID
+// reads the current version.
+func (c *Client) GetVersion(bucket, key, versionID string) GetResponse {
 	stats.Report(c.vu, objGetTotal, 1)
 	start := time.Now()
 
 	var objSize = 0
-	err := get(c.vu.Context(), c.cli, bucket, key, func(chunk []byte) {
+	err := get(c.vu.Context(), c.cli, bucket, key, versionID, func(chunk []byte) {
 		objSize += len(chunk)
 	})
 	if err != nil {
@@ -111,33 +245,41 @@ func get(
 	c *s3.Client,
 	bucket string,
 	key string,
+	versionID string,
 	onDataChunk func(chunk []byte),
 ) error {
 	var buf = make([]byte, 4*1024)
 
+	// Checksum mode makes the client validate payload against checksum
+	// stored with the object, mismatch is returned as read error.
 	obj, err := c.GetObject(ctx, &s3.GetObjectInput{
-		Bucket: aws.String(bucket),
-		Key:    aws.String(key),
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		VersionId:    optString(versionID),
+		ChecksumMode: types.ChecksumModeEnabled,
 	})
 	if err != nil {
 		return err
 	}
+	defer obj.Body.Close()
 
 	for {
 		n, err := obj.Body.Read(buf)
 		if n > 0 {
 			onDataChunk(buf[:n])
 		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
 		if err != nil {
-			break
+			return err
 		}
 	}
-	return nil
 }
 
 func (c *Client) VerifyHash(bucket, key, expectedHash string) VerifyHashResponse {
 	hasher := sha256.New()
-	err := get(c.vu.Context(), c.cli, bucket, key, func(data []byte) {
+	err := get(c.vu.Context(), c.cli, bucket, key, "", func(data []byte) {
 		hasher.Write(data)
 	})
 	if err != nil {
@@ -145,7 +287,7 @@ func (c *Client) VerifyHash(bucket, key, expectedHash string) VerifyHashResponse
 	}
 	actualHash := hex.EncodeToString(hasher.Sum(nil))
 	if actualHash != expectedHash {
-		return VerifyHashResponse{Success: true, Error: "hash mismatch"}
+		return VerifyHashResponse{Success: false, Error: "hash mismatch"}
 	}
 
 	return VerifyHashResponse{Success: true}
@@ -185,3 +327,191 @@ func (c *Client) CreateBucket(bucket string, params map[string]string) CreateBuc
 	stats.Report(c.vu, createBucketDuration, metrics.D(time.Since(start)))
 	return CreateBucketResponse{Success: true}
 }
+
+func (c *Client) PutTagging(bucket, key string, tags map[string]string) PutTaggingResponse {
+	stats.Report(c.vu, objPutTaggingTotal, 1)
+
+	tagSet := make([]types.Tag, 0, len(tags))
+	for k, v := range tags {
+		tagSet = append(tagSet, types.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+
+	start := time.Now()
+	_, err := c.cli.PutObjectTagging(c.vu.Context(), &s3.PutObjectTaggingInput{
+		Bucket:  aws.String(bucket),
+		Key:     aws.String(key),
+		Tagging: &types.Tagging{TagSet: tagSet},
+	})
+	if err != nil {
+		stats.Report(c.vu, objPutTaggingFails, 1)
+		return PutTaggingResponse{Success: false, Error: err.Error()}
+	}
+
+	stats.Report(c.vu, objPutTaggingDuration, metrics.D(time.Since(start)))
+	return PutTaggingResponse{Success: true}
+}
+
+func (c *Client) GetTagging(bucket, key string) GetTaggingResponse {
+	stats.Report(c.vu, objGetTaggingTotal, 1)
+	start := time.Now()
+
+	res, err := c.cli.GetObjectTagging(c.vu.Context(), &s3.GetObjectTaggingInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		stats.Report(c.vu, objGetTaggingFails, 1)
+		return GetTaggingResponse{Success: false, Error: err.Error()}
+	}
+
+	stats.Report(c.vu, objGetTaggingDuration, metrics.D(time.Since(start)))
+
+	tags := make(map[string]string, len(res.TagSet))
+	for _, tag := range res.TagSet {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return GetTaggingResponse{Success: true, Tags: tags}
+}
+
+func (c *Client) DeleteTagging(bucket, key string) DeleteTaggingResponse {
+	stats.Report(c.vu, objDeleteTaggingTotal, 1)
+	start := time.Now()
+
+	_, err := c.cli.DeleteObjectTagging(c.vu.Context(), &s3.DeleteObjectTaggingInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		stats.Report(c.vu, objDeleteTaggingFails, 1)
+		return DeleteTaggingResponse{Success: false, Error: err.Error()}
+	}
+
+	stats.Report(c.vu, objDeleteTaggingDuration, metrics.D(time.Since(start)))
+	return DeleteTaggingResponse{Success: true}
+}
+
+// Copy makes server-side copy of the object. The metadataDirective is either
+// COPY, REPLACE or empty for the gateway default.
+func (c *Client) Copy(srcBucket, srcKey, dstBucket, dstKey, metadataDirective string) CopyResponse {
+	stats.Report(c.vu, objCopyTotal, 1)
+	start := time.Now()
+
+	res, err := c.cli.CopyObject(c.vu.Context(), &s3.CopyObjectInput{
+		Bucket:            aws.String(dstBucket),
+		Key:               aws.String(dstKey),
+		CopySource:        aws.String(copySource(srcBucket, srcKey)),
+		MetadataDirective: types.MetadataDirective(strings.ToUpper(metadataDirective)),
+	})
+	if err != nil {
+		stats.Report(c.vu, objCopyFails, 1)
+		return CopyResponse{Success: false, Error: err.Error()}
+	}
+
+	stats.Report(c.vu, objCopyDuration, metrics.D(time.Since(start)))
+	return CopyResponse{Success: true, VersionID: aws.ToString(res.VersionId)}
+}
+
+// copySource returns URL-encoded 'bucket/key' value of x-amz-copy-source
+// header, '/' separators of the key are kept as is.
+func copySource(bucket, key string) string {
+	segments := strings.Split(key, "/")
+	for i := range segments {
+		segments[i] = url.PathEscape(segments[i])
+	}
+	return url.PathEscape(bucket) + "/" + strings.Join(segments, "/")
+}
+
+// DeleteMany removes objects with the specified keys in a single multi-object
+// delete request. Success is true if the request itself succeeded, results of
+// every key are returned separately.
+func (c *Client) DeleteMany(bucket string, keys []string) DeleteManyResponse {
+	stats.Report(c.vu, objDeleteManyTotal, 1)
+
+	objects := make([]types.ObjectIdentifier, len(keys))
+	for i := range keys {
+		objects[i].Key = aws.String(keys[i])
+	}
+
+	start := time.Now()
+	res, err := c.cli.DeleteObjects(c.vu.Context(), &s3.DeleteObjectsInput{
+		Bucket: aws.String(bucket),
+		Delete: &types.Delete{Objects: objects},
+	})
+	if err != nil {
+		stats.Report(c.vu, objDeleteManyFails, 1)
+		return DeleteManyResponse{Success: false, Error: err.Error()}
+	}
+
+	stats.Report(c.vu, objDeleteManyDuration, metrics.D(time.Since(start)))
+	stats.Report(c.vu, objDeleteManyKeys, float64(len(res.Deleted)))
+	stats.Report(c.vu, objDeleteManyKeyErrors, float64(len(res.Errors)))
+
+	results := make([]DeleteManyResult, 0, len(res.Deleted)+len(res.Errors))
+	for _, d := range res.Deleted {
+		results = append(results, DeleteManyResult{Key: aws.ToString(d.Key), Success: true})
+	}
+	for _, e := range res.Errors {
+		results = append(results, DeleteManyResult{
+			Key:   aws.ToString(e.Key),
+			Error: aws.ToString(e.Code) + ": " + aws.ToString(e.Message),
+		})
+	}
+	return DeleteManyResponse{Success: true, Results: results}
+}
+
+// calcChecksum returns base64 encoded checksum of the data in the format
+// expected by S3 checksum headers.
+func calcChecksum(alg types.ChecksumAlgorithm, data []byte) (string, error) {
+	var h hash.Hash
+	switch alg {
+	case types.ChecksumAlgorithmCrc32:
+		h = crc32.NewIEEE()
+	case types.ChecksumAlgorithmCrc32c:
+		h = crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	case types.ChecksumAlgorithmSha1:
+		h = sha1.New()
+	case types.ChecksumAlgorithmSha256:
+		h = sha256.New()
+	default:
+		return "", fmt.Errorf("unsupported checksum algorithm: '%s'", alg)
+	}
+	h.Write(data)
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+func setChecksum(input *s3.PutObjectInput, checksum string) {
+	switch input.ChecksumAlgorithm {
+	case types.ChecksumAlgorithmCrc32:
+		input.ChecksumCRC32 = aws.String(checksum)
+	case types.ChecksumAlgorithmCrc32c:
+		input.ChecksumCRC32C = aws.String(checksum)
+	case types.ChecksumAlgorithmSha1:
+		input.ChecksumSHA1 = aws.String(checksum)
+	case types.ChecksumAlgorithmSha256:
+		input.ChecksumSHA256 = aws.String(checksum)
+	}
+}
+
+func responseChecksum(res *s3.PutObjectOutput, alg types.ChecksumAlgorithm) string {
+	switch alg {
+	case types.ChecksumAlgorithmCrc32:
+		return aws.ToString(res.ChecksumCRC32)
+	case types.ChecksumAlgorithmCrc32c:
+		return aws.ToString(res.ChecksumCRC32C)
+	case types.ChecksumAlgorithmSha1:
+		return aws.ToString(res.ChecksumSHA1)
+	case types.ChecksumAlgorithmSha256:
+		return aws.ToString(res.ChecksumSHA256)
+	default:
+		return ""
+	}
+}
+
+// optString returns nil for empty strings, so that optional request
+// fields are omitted instead of being sent empty.
+func optString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return aws.String(s)
+}
@@ -0,0 +1,294 @@
+package s3
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/grafana/sobek"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecksum(t *testing.T) {
+	data := []byte("hello world")
+
+	for _, tc := range []struct {
+		alg      types.ChecksumAlgorithm
+		expected string
+		field    func(*s3.PutObjectInput) *string
+		output   func(string) *s3.PutObjectOutput
+	}{
+		{
+			alg:      types.ChecksumAlgorithmCrc32,
+			expected: "DUoRhQ==",
+			field:    func(in *s3.PutObjectInput) *string { return in.ChecksumCRC32 },
+			output:   func(v string) *s3.PutObjectOutput { return &s3.PutObjectOutput{ChecksumCRC32: aws.String(v)} },
+		},
+		{
+			alg:      types.ChecksumAlgorithmCrc32c,
+			expected: "yZRlqg==",
+			field:    func(in *s3.PutObjectInput) *string { return in.ChecksumCRC32C },
+			output:   func(v string) *s3.PutObjectOutput { return &s3.PutObjectOutput{ChecksumCRC32C: aws.String(v)} },
+		},
+		{
+			alg:      types.ChecksumAlgorithmSha1,
+			expected: "Kq5sNclPz7QV2+lfQIuc6R7oRu0=",
+			field:    func(in *s3.PutObjectInput) *string { return in.ChecksumSHA1 },
+			output:   func(v string) *s3.PutObjectOutput { return &s3.PutObjectOutput{ChecksumSHA1: aws.String(v)} },
+		},
+		{
+			alg:      types.ChecksumAlgorithmSha256,
+			expected: "uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek=",
+			field:    func(in *s3.PutObjectInput) *string { return in.ChecksumSHA256 },
+			output:   func(v string) *s3.PutObjectOutput { return &s3.PutObjectOutput{ChecksumSHA256: aws.String(v)} },
+		},
+	} {
+		t.Run(string(tc.alg), func(t *testing.T) {
+			checksum, err := calcChecksum(tc.alg, data)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, checksum)
+
+			input := &s3.PutObjectInput{ChecksumAlgorithm: tc.alg}
+			setChecksum(input, checksum)
+			require.Equal(t, tc.expected, aws.ToString(tc.field(input)))
+
+			require.Equal(t, tc.expected, responseChecksum(tc.output(checksum), tc.alg))
+			// Checksum of other algorithm is ignored
+			require.Empty(t, responseChecksum(tc.output(checksum), "MD5"))
+		})
+	}
+
+	_, err := calcChecksum("MD5", data)
+	require.Error(t, err)
+	require.Empty(t, responseChecksum(&s3.PutObjectOutput{}, types.ChecksumAlgorithmSha256))
+}
+
+func TestVerifyHash(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello world"))
+	}))
+	defer srv.Close()
+
+	cli, _ := newTestClient(t, srv.URL, nil)
+	hash := sha256.Sum256([]byte("hello world"))
+
+	resp := cli.VerifyHash("bucket", "key", hex.EncodeToString(hash[:]))
+	require.True(t, resp.Success, resp.Error)
+
+	resp = cli.VerifyHash("bucket", "key", "deadbeef")
+	require.False(t, resp.Success)
+	require.Equal(t, "hash mismatch", resp.Error)
+}
+
+func TestPutETag(t *testing.T) {
+	data := []byte("hello world")
+	etags := map[string]string{
+		"/bucket/md5":       md5Hex(data),
+		"/bucket/multipart": "b9a3e2d1f0c87a6b5e4d3c2b1a098f7e-2",
+		"/bucket/not-md5":   "etag",
+		"/bucket/mismatch":  md5Hex([]byte("other")),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		w.Header().Set("ETag", `"`+etags[r.URL.Path]+`"`)
+	}))
+	defer srv.Close()
+
+	cli, samples := newTestClient(t, srv.URL, nil)
+	rt := sobek.New()
+
+	for _, tc := range []struct {
+		key     string
+		success bool
+	}{
+		{key: "md5", success: true},
+		{key: "multipart", success: true},
+		{key: "not-md5", success: true},
+		{key: "mismatch", success: false},
+	} {
+		t.Run(tc.key, func(t *testing.T) {
+			resp := cli.Put("bucket", tc.key, rt.ToValue(rt.NewArrayBuffer(data)), PutParams{})
+			require.Equal(t, tc.success, resp.Success, resp.Error)
+			require.Equal(t, etags["/bucket/"+tc.key], resp.ETag)
+
+			integrityFails := len(collectSamples(samples)[objPutIntegrityFails])
+			if tc.success {
+				require.Zero(t, integrityFails)
+			} else {
+				require.Equal(t, "etag mismatch", resp.Error)
+				require.Equal(t, 1, integrityFails)
+			}
+		})
+	}
+}
+
+// recordedRequest is a request received by the test server.
+type recordedRequest struct {
+	method string
+	path   string
+	query  url.Values
+	header http.Header
+	body   []byte
+}
+
+// newRecordingServer starts server that records received requests and
+// responds with the body returned by respond.
+func newRecordingServer(t *testing.T, respond func(*http.Request) string) (*httptest.Server, chan recordedRequest) {
+	requests := make(chan recordedRequest, 100)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- recordedRequest{method: r.Method, path: r.URL.Path, query: r.URL.Query(), header: r.Header, body: body}
+		_, _ = io.WriteString(w, respond(r))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, requests
+}
+
+func TestVersions(t *testing.T) {
+	srv, requests := newRecordingServer(t, func(r *http.Request) string {
+		if r.Method == http.MethodGet {
+			return "hello world"
+		}
+		return ""
+	})
+	cli, _ := newTestClient(t, srv.URL, nil)
+
+	for _, versionID := range []string{"v1", ""} {
+		require.True(t, cli.GetVersion("bucket", "key", versionID).Success)
+		req := <-requests
+		require.Equal(t, http.MethodGet, req.method)
+		require.Equal(t, "/bucket/key", req.path)
+		require.Equal(t, versionID != "", req.query.Has("versionId"))
+		require.Equal(t, versionID, req.query.Get("versionId"))
+
+		require.True(t, cli.DeleteVersion("bucket", "key", versionID).Success)
+		req = <-requests
+		require.Equal(t, http.MethodDelete, req.method)
+		require.Equal(t, "/bucket/key", req.path)
+		require.Equal(t, versionID != "", req.query.Has("versionId"))
+		require.Equal(t, versionID, req.query.Get("versionId"))
+	}
+}
+
+func TestTagging(t *testing.T) {
+	type tagging struct {
+		Tags []struct {
+			Key   string
+			Value string
+		} `xml:"TagSet>Tag"`
+	}
+	tagMap := func(body []byte) map[string]string {
+		var tg tagging
+		require.NoError(t, xml.Unmarshal(body, &tg))
+		tags := make(map[string]string)
+		for _, tag := range tg.Tags {
+			tags[tag.Key] = tag.Value
+		}
+		return tags
+	}
+
+	var stored atomic.Pointer[string]
+	stored.Store(new(string))
+	srv, requests := newRecordingServer(t, func(r *http.Request) string {
+		if r.Method == http.MethodGet {
+			return *stored.Load()
+		}
+		return ""
+	})
+	cli, _ := newTestClient(t, srv.URL, nil)
+
+	for _, tags := range []map[string]string{
+		{"owner": "k6", "type": "image"},
+		{},
+	} {
+		require.True(t, cli.PutTagging("bucket", "key", tags).Success)
+		req := <-requests
+		require.Equal(t, http.MethodPut, req.method)
+		require.Equal(t, "/bucket/key", req.path)
+		require.True(t, req.query.Has("tagging"))
+		require.Equal(t, tags, tagMap(req.body))
+
+		body := string(req.body)
+		stored.Store(&body)
+		resp := cli.GetTagging("bucket", "key")
+		require.True(t, resp.Success, resp.Error)
+		require.Equal(t, tags, resp.Tags)
+		req = <-requests
+		require.Equal(t, http.MethodGet, req.method)
+		require.True(t, req.query.Has("tagging"))
+	}
+
+	require.True(t, cli.DeleteTagging("bucket", "key").Success)
+	req := <-requests
+	require.Equal(t, http.MethodDelete, req.method)
+	require.Equal(t, "/bucket/key", req.path)
+	require.True(t, req.query.Has("tagging"))
+}
+
+func TestCopy(t *testing.T) {
+	srv, requests := newRecordingServer(t, func(r *http.Request) string {
+		return "<CopyObjectResult><ETag>\"etag\"</ETag></CopyObjectResult>"
+	})
+	cli, _ := newTestClient(t, srv.URL, nil)
+
+	for _, tc := range []struct {
+		key    string
+		header string
+	}{
+		{key: "key", header: "src/key"},
+		{key: "dir/sub/cat.jpg", header: "src/dir/sub/cat.jpg"},
+		{key: "dir/a b%c?.txt", header: "src/dir/a%20b%25c%3F.txt"},
+	} {
+		resp := cli.Copy("src", tc.key, "dst", "copy", "replace")
+		require.True(t, resp.Success, resp.Error)
+
+		req := <-requests
+		require.Equal(t, http.MethodPut, req.method)
+		require.Equal(t, "/dst/copy", req.path)
+		require.Equal(t, tc.header, req.header.Get("X-Amz-Copy-Source"))
+		require.Equal(t, "REPLACE", req.header.Get("X-Amz-Metadata-Directive"))
+	}
+}
+
+func TestDeleteMany(t *testing.T) {
+	srv, requests := newRecordingServer(t, func(r *http.Request) string {
+		return "<DeleteResult>" +
+			"<Deleted><Key>a</Key></Deleted>" +
+			"<Error><Key>b</Key><Code>AccessDenied</Code><Message>Access Denied</Message></Error>" +
+			"<Deleted><Key>c</Key></Deleted>" +
+			"</DeleteResult>"
+	})
+	cli, samples := newTestClient(t, srv.URL, nil)
+
+	resp := cli.DeleteMany("bucket", []string{"a", "b", "c"})
+	require.True(t, resp.Success, resp.Error)
+	require.ElementsMatch(t, []DeleteManyResult{
+		{Key: "a", Success: true},
+		{Key: "b", Success: false, Error: "AccessDenied: Access Denied"},
+		{Key: "c", Success: true},
+	}, resp.Results)
+
+	req := <-requests
+	require.Equal(t, http.MethodPost, req.method)
+	require.True(t, req.query.Has("delete"))
+	var del struct {
+		Keys []string `xml:"Object>Key"`
+	}
+	require.NoError(t, xml.Unmarshal(req.body, &del))
+	require.Equal(t, []string{"a", "b", "c"}, del.Keys)
+
+	reported := collectSamples(samples)
+	require.Len(t, reported[objDeleteManyKeys], 1)
+	require.EqualValues(t, 2, reported[objDeleteManyKeys][0].Value)
+	require.Len(t, reported[objDeleteManyKeyErrors], 1)
+	require.EqualValues(t, 1, reported[objDeleteManyKeyErrors][0].Value)
+}
@@ -0,0 +1,294 @@
+package s3
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/nspcc-dev/xk6-neofs/internal/stats"
+	"go.k6.io/k6/metrics"
+)
+
+type (
+	PutRetentionResponse struct {
+		Success bool
+		Error   string
+	}
+
+	GetRetentionResponse struct {
+		Success     bool
+		Mode        string
+		RetainUntil string
+		Error       string
+	}
+
+	PutLegalHoldResponse struct {
+		Success bool
+		Error   string
+	}
+
+	GetLegalHoldResponse struct {
+		Success bool
+		Enabled bool
+		Error   string
+	}
+
+	PutBucketLockResponse struct {
+		Success bool
+		Error   string
+	}
+
+	// DeleteLockedResponse is a result of the delete attempt that is expected
+	// to be rejected because of object lock. Success is true only when the
+	// gateway rejected the request, it is false if the object was deleted
+	// despite the lock or the request failed for another reason.
+	DeleteLockedResponse struct {
+		Success bool
+		Error   string
+	}
+)
+
+// PutRetention sets retention of the object. The params is a dictionary with
+// `mode` (GOVERNANCE or COMPLIANCE), `retain_until` (RFC 3339 date or
+// duration relative to now, e.g. `24h`) and optional `version_id` and
+// `bypass_governance` keys.
+func (c *Client) PutRetention(bucket, key string, params map[string]string) PutRetentionResponse {
+	stats.Report(c.vu, objPutRetentionTotal, 1)
+
+	mode, err := parseRetentionMode(params["mode"])
+	if err != nil {
+		stats.Report(c.vu, objPutRetentionFails, 1)
+		return PutRetentionResponse{Success: false, Error: err.Error()}
+	}
+
+	retainUntil, err := parseRetainUntil(params["retain_until"])
+	if err != nil {
+		stats.Report(c.vu, objPutRetentionFails, 1)
+		return PutRetentionResponse{Success: false, Error: err.Error()}
+	}
+
+	var bypass bool
+	if bypassStr, ok := params["bypass_governance"]; ok {
+		if bypass, err = strconv.ParseBool(bypassStr); err != nil {
+			stats.Report(c.vu, objPutRetentionFails, 1)
+			return PutRetentionResponse{Success: false, Error: "invalid bypass_governance params"}
+		}
+	}
+
+	start := time.Now()
+	_, err = c.cli.PutObjectRetention(c.vu.Context(), &s3.PutObjectRetentionInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(key),
+		VersionId: optString(params["version_id"]),
+		Retention: &types.ObjectLockRetention{
+			Mode:            mode,
+			RetainUntilDate: aws.Time(retainUntil),
+		},
+		BypassGovernanceRetention: aws.Bool(bypass),
+	})
+	if err != nil {
+		stats.Report(c.vu, objPutRetentionFails, 1)
+		return PutRetentionResponse{Success: false, Error: err.Error()}
+	}
+
+	stats.Report(c.vu, objPutRetentionDuration, metrics.D(time.Since(start)))
+	return PutRetentionResponse{Success: true}
+}
+
+func (c *Client) GetRetention(bucket, key, versionID string) GetRetentionResponse {
+	stats.Report(c.vu, objGetRetentionTotal, 1)
+	start := time.Now()
+
+	res, err := c.cli.GetObjectRetention(c.vu.Context(), &s3.GetObjectRetentionInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(key),
+		VersionId: optString(versionID),
+	})
+	if err != nil {
+		stats.Report(c.vu, objGetRetentionFails, 1)
+		return GetRetentionResponse{Success: false, Error: err.Error()}
+	}
+
+	stats.Report(c.vu, objGetRetentionDuration, metrics.D(time.Since(start)))
+
+	resp := GetRetentionResponse{Success: true}
+	if res.Retention != nil {
+		resp.Mode = string(res.Retention.Mode)
+		if res.Retention.RetainUntilDate != nil {
+			resp.RetainUntil = res.Retention.RetainUntilDate.UTC().Format(time.RFC3339)
+		}
+	}
+	return resp
+}
+
+func (c *Client) PutLegalHold(bucket, key, versionID string, enabled bool) PutLegalHoldResponse {
+	stats.Report(c.vu, objPutLegalHoldTotal, 1)
+
+	status := types.ObjectLockLegalHoldStatusOff
+	if enabled {
+		status = types.ObjectLockLegalHoldStatusOn
+	}
+
+	start := time.Now()
+	_, err := c.cli.PutObjectLegalHold(c.vu.Context(), &s3.PutObjectLegalHoldInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(key),
+		VersionId: optString(versionID),
+		LegalHold: &types.ObjectLockLegalHold{Status: status},
+	})
+	if err != nil {
+		stats.Report(c.vu, objPutLegalHoldFails, 1)
+		return PutLegalHoldResponse{Success: false, Error: err.Error()}
+	}
+
+	stats.Report(c.vu, objPutLegalHoldDuration, metrics.D(time.Since(start)))
+	return PutLegalHoldResponse{Success: true}
+}
+
+func (c *Client) GetLegalHold(bucket, key, versionID string) GetLegalHoldResponse {
+	stats.Report(c.vu, objGetLegalHoldTotal, 1)
+	start := time.Now()
+
+	res, err := c.cli.GetObjectLegalHold(c.vu.Context(), &s3.GetObjectLegalHoldInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(key),
+		VersionId: optString(versionID),
+	})
+	if err != nil {
+		stats.Report(c.vu, objGetLegalHoldFails, 1)
+		return GetLegalHoldResponse{Success: false, Error: err.Error()}
+	}
+
+	stats.Report(c.vu, objGetLegalHoldDuration, metrics.D(time.Since(start)))
+
+	enabled := res.LegalHold != nil && res.LegalHold.Status == types.ObjectLockLegalHoldStatusOn
+	return GetLegalHoldResponse{Success: true, Enabled: enabled}
+}
+
+// PutBucketLock sets default object lock configuration of the bucket. The
+// params is a dictionary with `mode` (GOVERNANCE or COMPLIANCE) and either
+// `days` or `years` keys. Empty params enable object lock without default
+// retention.
+func (c *Client) PutBucketLock(bucket string, params map[string]string) PutBucketLockResponse {
+	stats.Report(c.vu, putBucketLockTotal, 1)
+
+	cfg := &types.ObjectLockConfiguration{
+		ObjectLockEnabled: types.ObjectLockEnabledEnabled,
+	}
+
+	if modeStr, ok := params["mode"]; ok {
+		mode, err := parseRetentionMode(modeStr)
+		if err != nil {
+			stats.Report(c.vu, putBucketLockFails, 1)
+			return PutBucketLockResponse{Success: false, Error: err.Error()}
+		}
+
+		retention := &types.DefaultRetention{Mode: mode}
+		if retention.Days, err = parseInt32Param(params, "days"); err != nil {
+			stats.Report(c.vu, putBucketLockFails, 1)
+			return PutBucketLockResponse{Success: false, Error: err.Error()}
+		}
+		if retention.Years, err = parseInt32Param(params, "years"); err != nil {
+			stats.Report(c.vu, putBucketLockFails, 1)
+			return PutBucketLockResponse{Success: false, Error: err.Error()}
+		}
+		cfg.Rule = &types.ObjectLockRule{DefaultRetention: retention}
+	}
+
+	start := time.Now()
+	_, err := c.cli.PutObjectLockConfiguration(c.vu.Context(), &s3.PutObjectLockConfigurationInput{
+		Bucket:                  aws.String(bucket),
+		ObjectLockConfiguration: cfg,
+	})
+	if err != nil {
+		stats.Report(c.vu, putBucketLockFails, 1)
+		return PutBucketLockResponse{Success: false, Error: err.Error()}
+	}
+
+	stats.Report(c.vu, putBucketLockDuration, metrics.D(time.Since(start)))
+	return PutBucketLockResponse{Success: true}
+}
+
+// DeleteLocked tries to delete the specified version of the locked object and
+// checks that the gateway rejects it. Rejections are counted in
+// aws_obj_locked_delete_rejected, while unexpected deletions and other errors
+// are counted in aws_obj_locked_delete_fails. The versionID is required,
+// because deletion without version in versioned bucket just puts a delete
+// marker and succeeds despite the lock.
+func (c *Client) DeleteLocked(bucket, key, versionID string) DeleteLockedResponse {
+	stats.Report(c.vu, objLockedDeleteTotal, 1)
+	if versionID == "" {
+		stats.Report(c.vu, objLockedDeleteFails, 1)
+		return DeleteLockedResponse{Success: false, Error: "version_id is required"}
+	}
+	start := time.Now()
+
+	_, err := c.cli.DeleteObject(c.vu.Context(), &s3.DeleteObjectInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(key),
+		VersionId: aws.String(versionID),
+	})
+	if err == nil {
+		stats.Report(c.vu, objLockedDeleteFails, 1)
+		return DeleteLockedResponse{Success: false, Error: "locked object has been deleted"}
+	}
+	if !isAccessDenied(err) {
+		stats.Report(c.vu, objLockedDeleteFails, 1)
+		return DeleteLockedResponse{Success: false, Error: err.Error()}
+	}
+
+	stats.Report(c.vu, objLockedDeleteRejected, 1)
+	stats.Report(c.vu, objLockedDeleteDuration, metrics.D(time.Since(start)))
+	return DeleteLockedResponse{Success: true}
+}
+
+func parseRetentionMode(s string) (types.ObjectLockRetentionMode, error) {
+	mode := types.ObjectLockRetentionMode(strings.ToUpper(s))
+	switch mode {
+	case types.ObjectLockRetentionModeGovernance, types.ObjectLockRetentionModeCompliance:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid mode params: '%s'", s)
+	}
+}
+
+// parseInt32Param returns nil if the parameter is not set.
+func parseInt32Param(params map[string]string, name string) (*int32, error) {
+	valStr, ok := params[name]
+	if !ok {
+		return nil, nil
+	}
+	val, err := strconv.ParseInt(valStr, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s params", name)
+	}
+	return aws.Int32(int32(val)), nil
+}
+
+func parseRetainUntil(s string) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(d).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid retain_until params: '%s'", s)
+	}
+	return t, nil
+}
+
+func isAccessDenied(err error) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "AccessDenied" {
+		return true
+	}
+	var respErr *smithyhttp.ResponseError
+	return errors.As(err, &respErr) && respErr.HTTPStatusCode() == http.StatusForbidden
+}
@@ -0,0 +1,77 @@
+package s3
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRetentionMode(t *testing.T) {
+	for _, tc := range []struct {
+		in       string
+		expected types.ObjectLockRetentionMode
+		err      bool
+	}{
+		{in: "GOVERNANCE", expected: types.ObjectLockRetentionModeGovernance},
+		{in: "compliance", expected: types.ObjectLockRetentionModeCompliance},
+		{in: "", err: true},
+		{in: "legal", err: true},
+	} {
+		mode, err := parseRetentionMode(tc.in)
+		if tc.err {
+			require.Error(t, err, tc.in)
+			continue
+		}
+		require.NoError(t, err, tc.in)
+		require.Equal(t, tc.expected, mode)
+	}
+}
+
+func TestParseRetainUntil(t *testing.T) {
+	until, err := parseRetainUntil("2030-01-02T03:04:05Z")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC), until)
+
+	until, err = parseRetainUntil("24h")
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now().Add(24*time.Hour), until, time.Minute)
+	require.Equal(t, time.UTC, until.Location())
+
+	for _, s := range []string{"", "tomorrow", "2030-01-02"} {
+		_, err = parseRetainUntil(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestIsAccessDenied(t *testing.T) {
+	responseError := func(status int) error {
+		return &smithyhttp.ResponseError{
+			Response: &smithyhttp.Response{Response: &http.Response{StatusCode: status}},
+			Err:      errors.New("response error"),
+		}
+	}
+
+	for _, tc := range []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "api error", err: &smithy.GenericAPIError{Code: "AccessDenied"}, expected: true},
+		{name: "wrapped api error", err: fmt.Errorf("delete: %w", &smithy.GenericAPIError{Code: "AccessDenied"}), expected: true},
+		{name: "forbidden", err: responseError(http.StatusForbidden), expected: true},
+		{name: "other api error", err: &smithy.GenericAPIError{Code: "NoSuchKey"}},
+		{name: "other status", err: responseError(http.StatusNotFound)},
+		{name: "plain error", err: errors.New("connection refused")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, isAccessDenied(tc.err))
+		})
+	}
+}
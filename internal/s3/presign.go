@@ -0,0 +1,145 @@
+package s3
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/grafana/sobek"
+	"github.com/nspcc-dev/xk6-neofs/internal/datagen"
+	"github.com/nspcc-dev/xk6-neofs/internal/stats"
+	"go.k6.io/k6/metrics"
+)
+
+type PresignResponse struct {
+	Success bool
+	URL     string `js:"url"`
+	Error   string
+}
+
+// presignedTags are added to the regular object metrics for requests made
+// with presigned URLs.
+var presignedTags = map[string]string{"presigned": "true"}
+
+// Presign returns URL that allows to perform the specified operation (GET,
+// PUT, HEAD or DELETE) on the object without credentials until expiry
+// duration (e.g. `15m`) passes. Presigning is done locally, so it isn't
+// reported in metrics.
+func (c *Client) Presign(method, bucket, key, expiry string) PresignResponse {
+	expires, err := time.ParseDuration(expiry)
+	if err != nil {
+		return PresignResponse{Success: false, Error: fmt.Sprintf("invalid value for 'expiry': '%s'", expiry)}
+	}
+
+	var (
+		ctx     = c.vu.Context()
+		presign = s3.NewPresignClient(c.cli, s3.WithPresignExpires(expires))
+		req     *v4.PresignedHTTPRequest
+	)
+
+	switch method = strings.ToUpper(method); method {
+	case http.MethodGet:
+		req, err = presign.PresignGetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	case http.MethodPut:
+		req, err = presign.PresignPutObject(ctx, &s3.PutObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	case http.MethodHead:
+		req, err = presign.PresignHeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	case http.MethodDelete:
+		req, err = presign.PresignDeleteObject(ctx, &s3.DeleteObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	default:
+		return PresignResponse{Success: false, Error: fmt.Sprintf("unsupported method: '%s'", method)}
+	}
+	if err != nil {
+		return PresignResponse{Success: false, Error: err.Error()}
+	}
+
+	return PresignResponse{Success: true, URL: req.URL}
+}
+
+// PutPresigned uploads payload with the URL returned by Presign using plain
+// HTTP client, so that request doesn't go through SDK signing. Payload is an
+// array buffer or a payload handle.
+func (c *Client) PutPresigned(url string, payload sobek.Value) PutResponse {
+	data, err := datagen.PayloadBytes(payload)
+	if err != nil {
+		panic(err)
+	}
+
+	stats.ReportWithTags(c.vu, objPutTotal, 1, presignedTags)
+	start := time.Now()
+
+	req, err := http.NewRequestWithContext(c.vu.Context(), http.MethodPut, url, bytes.NewReader(data))
+	if err != nil {
+		stats.ReportWithTags(c.vu, objPutFails, 1, presignedTags)
+		return PutResponse{Success: false, Error: err.Error()}
+	}
+
+	resp, err := c.httpCli.Do(req)
+	if err != nil {
+		stats.ReportWithTags(c.vu, objPutFails, 1, presignedTags)
+		return PutResponse{Success: false, Error: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	if err = checkStatus(resp); err != nil {
+		stats.ReportWithTags(c.vu, objPutFails, 1, presignedTags)
+		return PutResponse{Success: false, Error: err.Error()}
+	}
+
+	stats.ReportDataSent(c.vu, float64(len(data)))
+	stats.ReportWithTags(c.vu, objPutDuration, metrics.D(time.Since(start)), presignedTags)
+	return PutResponse{
+		Success:   true,
+		VersionID: resp.Header.Get("x-amz-version-id"),
+		ETag:      strings.Trim(resp.Header.Get("ETag"), `"`),
+	}
+}
+
+// GetPresigned reads object with the URL returned by Presign using plain HTTP
+// client, so that request doesn't go through SDK signing.
+func (c *Client) GetPresigned(url string) GetResponse {
+	stats.ReportWithTags(c.vu, objGetTotal, 1, presignedTags)
+	start := time.Now()
+
+	req, err := http.NewRequestWithContext(c.vu.Context(), http.MethodGet, url, nil)
+	if err != nil {
+		stats.ReportWithTags(c.vu, objGetFails, 1, presignedTags)
+		return GetResponse{Success: false, Error: err.Error()}
+	}
+
+	resp, err := c.httpCli.Do(req)
+	if err != nil {
+		stats.ReportWithTags(c.vu, objGetFails, 1, presignedTags)
+		return GetResponse{Success: false, Error: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	if err = checkStatus(resp); err != nil {
+		stats.ReportWithTags(c.vu, objGetFails, 1, presignedTags)
+		return GetResponse{Success: false, Error: err.Error()}
+	}
+
+	objSize, err := io.Copy(io.Discard, resp.Body)
+	if err != nil {
+		stats.ReportWithTags(c.vu, objGetFails, 1, presignedTags)
+		return GetResponse{Success: false, Error: err.Error()}
+	}
+
+	stats.ReportWithTags(c.vu, objGetDuration, metrics.D(time.Since(start)), presignedTags)
+	stats.ReportDataReceived(c.vu, float64(objSize))
+	return GetResponse{Success: true}
+}
+
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+}
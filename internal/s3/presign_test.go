@@ -0,0 +1,93 @@
+package s3
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/grafana/sobek"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresign(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		objects = make(map[string][]byte)
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("X-Amz-Signature") == "" || q.Get("X-Amz-Expires") != "900" ||
+			r.Header.Get("Authorization") != "" {
+			http.Error(w, "not presigned", http.StatusForbidden)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			objects[r.URL.Path], _ = io.ReadAll(r.Body)
+			w.Header().Set("ETag", `"etag"`)
+		case http.MethodGet:
+			data, ok := objects[r.URL.Path]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write(data)
+		}
+	}))
+	defer srv.Close()
+
+	cli, samples := newTestClient(t, srv.URL, nil)
+	rt := sobek.New()
+
+	for _, method := range []string{"get", "PUT", "head", "delete"} {
+		resp := cli.Presign(method, "bucket", "key", "15m")
+		require.True(t, resp.Success, resp.Error)
+
+		u, err := url.Parse(resp.URL)
+		require.NoError(t, err)
+		require.Equal(t, "/bucket/key", u.Path)
+		require.Equal(t, "900", u.Query().Get("X-Amz-Expires"))
+	}
+
+	put := cli.Presign("put", "bucket", "key", "15m")
+	get := cli.Presign("get", "bucket", "key", "15m")
+	missing := cli.Presign("get", "bucket", "missing", "15m")
+
+	resp := cli.PutPresigned(put.URL, rt.ToValue(rt.NewArrayBuffer([]byte("hello world"))))
+	require.True(t, resp.Success, resp.Error)
+	require.Equal(t, "etag", resp.ETag)
+	require.Equal(t, []byte("hello world"), objects["/bucket/key"])
+
+	require.True(t, cli.GetPresigned(get.URL).Success)
+
+	res := cli.GetPresigned(missing.URL)
+	require.False(t, res.Success)
+	require.Contains(t, res.Error, "unexpected status 404")
+
+	// Presigning is local and isn't reported, failed requests are.
+	reported := collectSamples(samples)
+	require.Len(t, reported[objPutTotal], 1)
+	require.Empty(t, reported[objPutFails])
+	require.Len(t, reported[objGetTotal], 2)
+	require.Len(t, reported[objGetFails], 1)
+	for _, s := range reported[objGetFails] {
+		presigned, _ := s.Tags.Get("presigned")
+		require.Equal(t, "true", presigned)
+	}
+
+	for _, tc := range []struct{ method, expiry string }{
+		{method: "post", expiry: "15m"},
+		{method: "get", expiry: "15"},
+	} {
+		require.False(t, cli.Presign(tc.method, "bucket", "key", tc.expiry).Success)
+	}
+	require.Empty(t, collectSamples(samples))
+
+	require.Panics(t, func() { cli.PutPresigned(put.URL, rt.ToValue("hello world")) })
+}
@@ -0,0 +1,123 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/ratelimit"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/nspcc-dev/xk6-neofs/internal/stats"
+	"go.k6.io/k6/js/modules"
+	"go.k6.io/k6/metrics"
+)
+
+// retryErrorClasses maps names of error classes accepted in 'retry_errors'
+// parameter to the checks of the standard retryer.
+var retryErrorClasses = map[string][]retry.IsErrorRetryable{
+	"connection": {retry.RetryableConnectionError{}, retry.RetryableError{}},
+	"5xx":        {retry.RetryableHTTPStatusCode{Codes: retry.DefaultRetryableHTTPStatusCodes}},
+	"timeout":    {retry.RetryableErrorCode{Codes: retry.DefaultRetryableErrorCodes}},
+	"throttle":   {retry.RetryableErrorCode{Codes: retry.DefaultThrottleErrorCodes}},
+}
+
+type attemptCounterKey struct{}
+
+// newRetryer creates retryer from 'retry_max_attempts', 'retry_max_backoff'
+// and 'retry_errors' connection parameters. Without 'retry_max_attempts' (or
+// with value 1) failed requests are not retried at all.
+func newRetryer(params map[string]string) (aws.Retryer, error) {
+	maxAttempts := 1
+	if maxAttemptsStr, ok := params["retry_max_attempts"]; ok {
+		var err error
+		if maxAttempts, err = strconv.Atoi(maxAttemptsStr); err != nil || maxAttempts < 1 {
+			return nil, fmt.Errorf("invalid value for 'retry_max_attempts': '%s'", maxAttemptsStr)
+		}
+	}
+	if maxAttempts == 1 {
+		return aws.NopRetryer{}, nil
+	}
+
+	maxBackoff := retry.DefaultMaxBackoff
+	if maxBackoffStr, ok := params["retry_max_backoff"]; ok {
+		var err error
+		if maxBackoff, err = time.ParseDuration(maxBackoffStr); err != nil {
+			return nil, fmt.Errorf("invalid value for 'retry_max_backoff': '%s'", maxBackoffStr)
+		}
+	}
+
+	retryables := retry.DefaultRetryables
+	if classesStr, ok := params["retry_errors"]; ok {
+		retryables = []retry.IsErrorRetryable{retry.NoRetryCanceledError{}}
+		for class := range strings.SplitSeq(classesStr, ",") {
+			checks, ok := retryErrorClasses[strings.TrimSpace(class)]
+			if !ok {
+				return nil, fmt.Errorf("invalid value for 'retry_errors': unknown error class '%s'", class)
+			}
+			retryables = append(retryables, checks...)
+		}
+	}
+
+	return retry.NewStandard(func(o *retry.StandardOptions) {
+		o.MaxAttempts = maxAttempts
+		o.MaxBackoff = maxBackoff
+		o.Retryables = retryables
+		// retry quota of the default retryer prevents retries exactly when
+		// gateway sheds the load, which is the case retries are enabled for
+		o.RateLimiter = ratelimit.None
+	}), nil
+}
+
+// attemptMetrics returns stack option that reports latency of every request
+// attempt tagged with operation name and attempt number, and the number of
+// retries of every operation. It works without retries too, then every
+// operation has exactly one attempt. Stacks without retry step (presigning
+// doesn't send requests) are left as is.
+func attemptMetrics(vu modules.VU) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		if _, ok := stack.Finalize.Get("Retry"); !ok {
+			return nil
+		}
+
+		err := stack.Initialize.Add(middleware.InitializeMiddlewareFunc("AttemptCounter",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (
+				middleware.InitializeOutput, middleware.Metadata, error,
+			) {
+				attempts := new(int)
+				ctx = middleware.WithStackValue(ctx, attemptCounterKey{}, attempts)
+
+				out, md, err := next.HandleInitialize(ctx, in)
+				if *attempts > 1 {
+					stats.Report(vu, retriesTotal, float64(*attempts-1))
+				}
+				return out, md, err
+			}), middleware.Before)
+		if err != nil {
+			return err
+		}
+
+		return stack.Finalize.Insert(middleware.FinalizeMiddlewareFunc("AttemptMetrics",
+			func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (
+				middleware.FinalizeOutput, middleware.Metadata, error,
+			) {
+				attempt := 1
+				if attempts, ok := middleware.GetStackValue(ctx, attemptCounterKey{}).(*int); ok {
+					*attempts++
+					attempt = *attempts
+				}
+
+				start := time.Now()
+				out, md, err := next.HandleFinalize(ctx, in)
+				stats.ReportWithTags(vu, attemptDuration, metrics.D(time.Since(start)), map[string]string{
+					"operation": awsmiddleware.GetOperationName(ctx),
+					"attempt":   strconv.Itoa(attempt),
+				})
+				return out, md, err
+			}), "Retry", middleware.After)
+	}
+}
@@ -0,0 +1,138 @@
+package s3
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/require"
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+	"go.k6.io/k6/metrics"
+)
+
+// newTestClient connects to the endpoint with VU collecting reported samples.
+func newTestClient(t *testing.T, endpoint string, params map[string]string) (*Client, chan metrics.SampleContainer) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "access")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_REGION", "us-east-1")
+
+	registry := metrics.NewRegistry()
+	samples := make(chan metrics.SampleContainer, 1000)
+	vu := &modulestest.VU{
+		CtxField: context.Background(),
+		StateField: &lib.State{
+			Samples:        samples,
+			Tags:           lib.NewVUStateTags(registry.RootTagSet()),
+			BuiltinMetrics: metrics.RegisterBuiltinMetrics(registry),
+		},
+	}
+
+	cli, err := (&S3{vu: vu}).Connect(endpoint, params)
+	require.NoError(t, err)
+	return cli, samples
+}
+
+// collectSamples returns samples reported so far grouped by metric.
+func collectSamples(samples chan metrics.SampleContainer) map[*metrics.Metric][]metrics.Sample {
+	res := make(map[*metrics.Metric][]metrics.Sample)
+	for {
+		select {
+		case c := <-samples:
+			for _, s := range c.GetSamples() {
+				res[s.Metric] = append(res[s.Metric], s)
+			}
+		default:
+			return res
+		}
+	}
+}
+
+func TestNewRetryer(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		params   map[string]string
+		attempts int
+		err      bool
+	}{
+		{name: "default", params: map[string]string{}, attempts: 1},
+		{name: "single attempt", params: map[string]string{"retry_max_attempts": "1"}, attempts: 1},
+		{name: "retries", params: map[string]string{
+			"retry_max_attempts": "5",
+			"retry_max_backoff":  "1s",
+			"retry_errors":       "connection, 5xx,timeout,throttle",
+		}, attempts: 5},
+		{name: "zero attempts", params: map[string]string{"retry_max_attempts": "0"}, err: true},
+		{name: "negative attempts", params: map[string]string{"retry_max_attempts": "-1"}, err: true},
+		{name: "invalid attempts", params: map[string]string{"retry_max_attempts": "many"}, err: true},
+		{name: "invalid backoff", params: map[string]string{"retry_max_attempts": "2", "retry_max_backoff": "1"}, err: true},
+		{name: "unknown error class", params: map[string]string{"retry_max_attempts": "2", "retry_errors": "5xx,4xx"}, err: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			retryer, err := newRetryer(tc.params)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.attempts, retryer.MaxAttempts())
+			if tc.attempts == 1 {
+				require.IsType(t, aws.NopRetryer{}, retryer)
+			}
+		})
+	}
+}
+
+func TestAttemptMetrics(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		name     string
+		attempts string
+		success  bool
+		tags     []string
+		retries  float64
+	}{
+		{name: "without retries", attempts: "1", success: false, tags: []string{"1"}},
+		{name: "with retries", attempts: "3", success: true, tags: []string{"1", "2"}, retries: 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			requests.Store(0)
+			cli, samples := newTestClient(t, srv.URL, map[string]string{
+				"retry_max_attempts": tc.attempts,
+				"retry_max_backoff":  "1ms",
+			})
+
+			resp := cli.Delete("bucket", "key")
+			require.Equal(t, tc.success, resp.Success, resp.Error)
+
+			reported := collectSamples(samples)
+
+			var tags []string
+			for _, s := range reported[attemptDuration] {
+				attempt, _ := s.Tags.Get("attempt")
+				operation, _ := s.Tags.Get("operation")
+				require.Equal(t, "DeleteObject", operation)
+				tags = append(tags, attempt)
+			}
+			require.Equal(t, tc.tags, tags)
+
+			var retries float64
+			for _, s := range reported[retriesTotal] {
+				retries += s.Value
+			}
+			require.Equal(t, tc.retries, retries)
+		})
+	}
+}
@@ -1,10 +1,8 @@
 package s3
 
 import (
-	"crypto/tls"
 	"fmt"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -29,9 +27,37 @@ var (
 	_ modules.Module   = &RootModule{}
 
 	objPutTotal, objPutFails, objPutDuration                   *metrics.Metric
+	objPutIntegrityFails                                       *metrics.Metric
 	objGetTotal, objGetFails, objGetDuration                   *metrics.Metric
 	objDeleteTotal, objDeleteFails, objDeleteDuration          *metrics.Metric
 	createBucketTotal, createBucketFails, createBucketDuration *metrics.Metric
+
+	objPutTaggingTotal, objPutTaggingFails, objPutTaggingDuration          *metrics.Metric
+	objGetTaggingTotal, objGetTaggingFails, objGetTaggingDuration          *metrics.Metric
+	objDeleteTaggingTotal, objDeleteTaggingFails, objDeleteTaggingDuration *metrics.Metric
+
+	objPutRetentionTotal, objPutRetentionFails, objPutRetentionDuration                          *metrics.Metric
+	objGetRetentionTotal, objGetRetentionFails, objGetRetentionDuration                          *metrics.Metric
+	objPutLegalHoldTotal, objPutLegalHoldFails, objPutLegalHoldDuration                          *metrics.Metric
+	objGetLegalHoldTotal, objGetLegalHoldFails, objGetLegalHoldDuration                          *metrics.Metric
+	putBucketLockTotal, putBucketLockFails, putBucketLockDuration                                *metrics.Metric
+	objLockedDeleteTotal, objLockedDeleteRejected, objLockedDeleteFails, objLockedDeleteDuration *metrics.Metric
+
+	objCopyTotal, objCopyFails, objCopyDuration                   *metrics.Metric
+	objDeleteManyTotal, objDeleteManyFails, objDeleteManyDuration *metrics.Metric
+	objDeleteManyKeys, objDeleteManyKeyErrors                     *metrics.Metric
+
+	deleteBucketTotal, deleteBucketFails, deleteBucketDuration          *metrics.Metric
+	listBucketsTotal, listBucketsFails, listBucketsDuration             *metrics.Metric
+	putBucketPolicyTotal, putBucketPolicyFails, putBucketPolicyDuration *metrics.Metric
+	getBucketPolicyTotal, getBucketPolicyFails, getBucketPolicyDuration *metrics.Metric
+	putBucketCorsTotal, putBucketCorsFails, putBucketCorsDuration       *metrics.Metric
+	getBucketCorsTotal, getBucketCorsFails, getBucketCorsDuration       *metrics.Metric
+	putBucketACLTotal, putBucketACLFails, putBucketACLDuration          *metrics.Metric
+	getBucketACLTotal, getBucketACLFails, getBucketACLDuration          *metrics.Metric
+
+	retriesTotal, attemptDuration *metrics.Metric
+	connNewTotal, connReusedTotal *metrics.Metric
 )
 
 func init() {
@@ -57,13 +83,6 @@ func (s *S3) Connect(endpoint string, params map[string]string) (*Client, error)
 		return nil, fmt.Errorf("configuration error: %w", err)
 	}
 
-	var noVerifySSL bool
-	if noVerifySSLStr, ok := params["no_verify_ssl"]; ok {
-		if noVerifySSL, err = strconv.ParseBool(noVerifySSLStr); err != nil {
-			return nil, fmt.Errorf("invalid value for 'no_verify_ssl': '%s'", noVerifySSLStr)
-		}
-	}
-
 	var timeout time.Duration
 	if timeoutStr, ok := params["timeout"]; ok {
 		if timeout, err = time.ParseDuration(timeoutStr); err != nil {
@@ -71,22 +90,33 @@ func (s *S3) Connect(endpoint string, params map[string]string) (*Client, error)
 		}
 	}
 
+	retryer, err := newRetryer(params)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, err := newTransport(params)
+	if err != nil {
+		return nil, err
+	}
+
+	httpCli := &http.Client{
+		Transport: &connStatsTransport{vu: s.vu, next: transport},
+		Timeout:   timeout,
+	}
+
 	cli := s3.NewFromConfig(cfg, func(options *s3.Options) {
 		options.DisableLogOutputChecksumValidationSkipped = true
+		// validate checksums of all responses that have them
+		options.ResponseChecksumValidation = aws.ResponseChecksumValidationWhenSupported
 		options.BaseEndpoint = aws.String(endpoint)
 		// use 'domain/bucket/key' instead of default 'bucket.domain/key' scheme
 		options.UsePathStyle = true
-		// do not retry failed requests, by default client does up to 3 retry
-		options.Retryer = aws.NopRetryer{}
-		// s3 sometimes use self-signed certs
-		options.HTTPClient = &http.Client{
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{
-					InsecureSkipVerify: noVerifySSL,
-				},
-			},
-			Timeout: timeout,
-		}
+		// do not retry failed requests unless retries are explicitly configured,
+		// by default client does up to 3 retry
+		options.Retryer = retryer
+		options.HTTPClient = httpCli
+		options.APIOptions = append(options.APIOptions, attemptMetrics(s.vu))
 	})
 
 	// register metrics
@@ -94,6 +124,7 @@ func (s *S3) Connect(endpoint string, params map[string]string) (*Client, error)
 	objPutTotal, _ = registry.NewMetric("aws_obj_put_total", metrics.Counter)
 	objPutFails, _ = registry.NewMetric("aws_obj_put_fails", metrics.Counter)
 	objPutDuration, _ = registry.NewMetric("aws_obj_put_duration", metrics.Trend, metrics.Time)
+	objPutIntegrityFails, _ = registry.NewMetric("aws_obj_put_integrity_fails", metrics.Counter)
 
 	objGetTotal, _ = registry.NewMetric("aws_obj_get_total", metrics.Counter)
 	objGetFails, _ = registry.NewMetric("aws_obj_get_fails", metrics.Counter)
@@ -107,8 +138,94 @@ func (s *S3) Connect(endpoint string, params map[string]string) (*Client, error)
 	createBucketFails, _ = registry.NewMetric("aws_create_bucket_fails", metrics.Counter)
 	createBucketDuration, _ = registry.NewMetric("aws_create_bucket_duration", metrics.Trend, metrics.Time)
 
+	objPutTaggingTotal, _ = registry.NewMetric("aws_obj_put_tagging_total", metrics.Counter)
+	objPutTaggingFails, _ = registry.NewMetric("aws_obj_put_tagging_fails", metrics.Counter)
+	objPutTaggingDuration, _ = registry.NewMetric("aws_obj_put_tagging_duration", metrics.Trend, metrics.Time)
+
+	objGetTaggingTotal, _ = registry.NewMetric("aws_obj_get_tagging_total", metrics.Counter)
+	objGetTaggingFails, _ = registry.NewMetric("aws_obj_get_tagging_fails", metrics.Counter)
+	objGetTaggingDuration, _ = registry.NewMetric("aws_obj_get_tagging_duration", metrics.Trend, metrics.Time)
+
+	objDeleteTaggingTotal, _ = registry.NewMetric("aws_obj_delete_tagging_total", metrics.Counter)
+	objDeleteTaggingFails, _ = registry.NewMetric("aws_obj_delete_tagging_fails", metrics.Counter)
+	objDeleteTaggingDuration, _ = registry.NewMetric("aws_obj_delete_tagging_duration", metrics.Trend, metrics.Time)
+
+	objPutRetentionTotal, _ = registry.NewMetric("aws_obj_put_retention_total", metrics.Counter)
+	objPutRetentionFails, _ = registry.NewMetric("aws_obj_put_retention_fails", metrics.Counter)
+	objPutRetentionDuration, _ = registry.NewMetric("aws_obj_put_retention_duration", metrics.Trend, metrics.Time)
+
+	objGetRetentionTotal, _ = registry.NewMetric("aws_obj_get_retention_total", metrics.Counter)
+	objGetRetentionFails, _ = registry.NewMetric("aws_obj_get_retention_fails", metrics.Counter)
+	objGetRetentionDuration, _ = registry.NewMetric("aws_obj_get_retention_duration", metrics.Trend, metrics.Time)
+
+	objPutLegalHoldTotal, _ = registry.NewMetric("aws_obj_put_legal_hold_total", metrics.Counter)
+	objPutLegalHoldFails, _ = registry.NewMetric("aws_obj_put_legal_hold_fails", metrics.Counter)
+	objPutLegalHoldDuration, _ = registry.NewMetric("aws_obj_put_legal_hold_duration", metrics.Trend, metrics.Time)
+
+	objGetLegalHoldTotal, _ = registry.NewMetric("aws_obj_get_legal_hold_total", metrics.Counter)
+	objGetLegalHoldFails, _ = registry.NewMetric("aws_obj_get_legal_hold_fails", metrics.Counter)
+	objGetLegalHoldDuration, _ = registry.NewMetric("aws_obj_get_legal_hold_duration", metrics.Trend, metrics.Time)
+
+	putBucketLockTotal, _ = registry.NewMetric("aws_put_bucket_lock_total", metrics.Counter)
+	putBucketLockFails, _ = registry.NewMetric("aws_put_bucket_lock_fails", metrics.Counter)
+	putBucketLockDuration, _ = registry.NewMetric("aws_put_bucket_lock_duration", metrics.Trend, metrics.Time)
+
+	objLockedDeleteTotal, _ = registry.NewMetric("aws_obj_locked_delete_total", metrics.Counter)
+	objLockedDeleteRejected, _ = registry.NewMetric("aws_obj_locked_delete_rejected", metrics.Counter)
+	objLockedDeleteFails, _ = registry.NewMetric("aws_obj_locked_delete_fails", metrics.Counter)
+	objLockedDeleteDuration, _ = registry.NewMetric("aws_obj_locked_delete_duration", metrics.Trend, metrics.Time)
+
+	objCopyTotal, _ = registry.NewMetric("aws_obj_copy_total", metrics.Counter)
+	objCopyFails, _ = registry.NewMetric("aws_obj_copy_fails", metrics.Counter)
+	objCopyDuration, _ = registry.NewMetric("aws_obj_copy_duration", metrics.Trend, metrics.Time)
+
+	objDeleteManyTotal, _ = registry.NewMetric("aws_obj_delete_many_total", metrics.Counter)
+	objDeleteManyFails, _ = registry.NewMetric("aws_obj_delete_many_fails", metrics.Counter)
+	objDeleteManyDuration, _ = registry.NewMetric("aws_obj_delete_many_duration", metrics.Trend, metrics.Time)
+	objDeleteManyKeys, _ = registry.NewMetric("aws_obj_delete_many_keys", metrics.Counter)
+	objDeleteManyKeyErrors, _ = registry.NewMetric("aws_obj_delete_many_key_errors", metrics.Counter)
+
+	deleteBucketTotal, _ = registry.NewMetric("aws_delete_bucket_total", metrics.Counter)
+	deleteBucketFails, _ = registry.NewMetric("aws_delete_bucket_fails", metrics.Counter)
+	deleteBucketDuration, _ = registry.NewMetric("aws_delete_bucket_duration", metrics.Trend, metrics.Time)
+
+	listBucketsTotal, _ = registry.NewMetric("aws_list_buckets_total", metrics.Counter)
+	listBucketsFails, _ = registry.NewMetric("aws_list_buckets_fails", metrics.Counter)
+	listBucketsDuration, _ = registry.NewMetric("aws_list_buckets_duration", metrics.Trend, metrics.Time)
+
+	putBucketPolicyTotal, _ = registry.NewMetric("aws_put_bucket_policy_total", metrics.Counter)
+	putBucketPolicyFails, _ = registry.NewMetric("aws_put_bucket_policy_fails", metrics.Counter)
+	putBucketPolicyDuration, _ = registry.NewMetric("aws_put_bucket_policy_duration", metrics.Trend, metrics.Time)
+
+	getBucketPolicyTotal, _ = registry.NewMetric("aws_get_bucket_policy_total", metrics.Counter)
+	getBucketPolicyFails, _ = registry.NewMetric("aws_get_bucket_policy_fails", metrics.Counter)
+	getBucketPolicyDuration, _ = registry.NewMetric("aws_get_bucket_policy_duration", metrics.Trend, metrics.Time)
+
+	putBucketCorsTotal, _ = registry.NewMetric("aws_put_bucket_cors_total", metrics.Counter)
+	putBucketCorsFails, _ = registry.NewMetric("aws_put_bucket_cors_fails", metrics.Counter)
+	putBucketCorsDuration, _ = registry.NewMetric("aws_put_bucket_cors_duration", metrics.Trend, metrics.Time)
+
+	getBucketCorsTotal, _ = registry.NewMetric("aws_get_bucket_cors_total", metrics.Counter)
+	getBucketCorsFails, _ = registry.NewMetric("aws_get_bucket_cors_fails", metrics.Counter)
+	getBucketCorsDuration, _ = registry.NewMetric("aws_get_bucket_cors_duration", metrics.Trend, metrics.Time)
+
+	putBucketACLTotal, _ = registry.NewMetric("aws_put_bucket_acl_total", metrics.Counter)
+	putBucketACLFails, _ = registry.NewMetric("aws_put_bucket_acl_fails", metrics.Counter)
+	putBucketACLDuration, _ = registry.NewMetric("aws_put_bucket_acl_duration", metrics.Trend, metrics.Time)
+
+	getBucketACLTotal, _ = registry.NewMetric("aws_get_bucket_acl_total", metrics.Counter)
+	getBucketACLFails, _ = registry.NewMetric("aws_get_bucket_acl_fails", metrics.Counter)
+	getBucketACLDuration, _ = registry.NewMetric("aws_get_bucket_acl_duration", metrics.Trend, metrics.Time)
+
+	retriesTotal, _ = registry.NewMetric("aws_retries_total", metrics.Counter)
+	attemptDuration, _ = registry.NewMetric("aws_attempt_duration", metrics.Trend, metrics.Time)
+
+	connNewTotal, _ = registry.NewMetric("aws_conn_new_total", metrics.Counter)
+	connReusedTotal, _ = registry.NewMetric("aws_conn_reused_total", metrics.Counter)
+
 	return &Client{
-		vu:  s.vu,
-		cli: cli,
+		vu:      s.vu,
+		cli:     cli,
+		httpCli: httpCli,
 	}, nil
 }
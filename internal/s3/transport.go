@@ -0,0 +1,117 @@
+package s3
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/nspcc-dev/xk6-neofs/internal/stats"
+	"go.k6.io/k6/js/modules"
+)
+
+// connStatsTransport reports whether connections used for requests were
+// established anew or reused from the idle pool.
+type connStatsTransport struct {
+	vu   modules.VU
+	next http.RoundTripper
+}
+
+func (t *connStatsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				stats.Report(t.vu, connReusedTotal, 1)
+			} else {
+				stats.Report(t.vu, connNewTotal, 1)
+			}
+		},
+	}
+	return t.next.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+}
+
+// newTransport creates HTTP transport configured with connection parameters.
+func newTransport(params map[string]string) (*http.Transport, error) {
+	var err error
+
+	var noVerifySSL bool
+	if noVerifySSLStr, ok := params["no_verify_ssl"]; ok {
+		if noVerifySSL, err = strconv.ParseBool(noVerifySSLStr); err != nil {
+			return nil, fmt.Errorf("invalid value for 'no_verify_ssl': '%s'", noVerifySSLStr)
+		}
+	}
+
+	// s3 sometimes use self-signed certs
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: noVerifySSL,
+	}
+
+	if caFile, ok := params["ca_file"]; ok {
+		caPEM, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA file: %w", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in CA file '%s'", caFile)
+		}
+	}
+
+	certFile, hasCert := params["cert_file"]
+	keyFile, hasKey := params["key_file"]
+	if hasCert != hasKey {
+		return nil, errors.New("both 'cert_file' and 'key_file' must be provided for client certificate")
+	}
+	if hasCert {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport := &http.Transport{
+		TLSClientConfig: tlsConfig,
+	}
+
+	for _, p := range []struct {
+		name  string
+		field *int
+	}{
+		{"max_conns_per_host", &transport.MaxConnsPerHost},
+		{"max_idle_conns", &transport.MaxIdleConns},
+		{"max_idle_conns_per_host", &transport.MaxIdleConnsPerHost},
+	} {
+		if valStr, ok := params[p.name]; ok {
+			if *p.field, err = strconv.Atoi(valStr); err != nil || *p.field < 0 {
+				return nil, fmt.Errorf("invalid value for '%s': '%s'", p.name, valStr)
+			}
+		}
+	}
+
+	if idleTimeoutStr, ok := params["idle_timeout"]; ok {
+		if transport.IdleConnTimeout, err = time.ParseDuration(idleTimeoutStr); err != nil {
+			return nil, fmt.Errorf("invalid value for 'idle_timeout': '%s'", idleTimeoutStr)
+		}
+	}
+
+	if disableKeepAliveStr, ok := params["disable_keep_alive"]; ok {
+		if transport.DisableKeepAlives, err = strconv.ParseBool(disableKeepAliveStr); err != nil {
+			return nil, fmt.Errorf("invalid value for 'disable_keep_alive': '%s'", disableKeepAliveStr)
+		}
+	}
+
+	// transport with custom TLS config doesn't try HTTP/2 unless forced to
+	if http2Str, ok := params["http2"]; ok {
+		if transport.ForceAttemptHTTP2, err = strconv.ParseBool(http2Str); err != nil {
+			return nil, fmt.Errorf("invalid value for 'http2': '%s'", http2Str)
+		}
+	}
+
+	return transport, nil
+}
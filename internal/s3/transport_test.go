@@ -0,0 +1,111 @@
+package s3
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTransport(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		transport, err := newTransport(map[string]string{
+			"no_verify_ssl":           "true",
+			"max_conns_per_host":      "10",
+			"max_idle_conns":          "20",
+			"max_idle_conns_per_host": "5",
+			"idle_timeout":            "30s",
+			"disable_keep_alive":      "true",
+			"http2":                   "true",
+		})
+		require.NoError(t, err)
+		require.True(t, transport.TLSClientConfig.InsecureSkipVerify)
+		require.Equal(t, 10, transport.MaxConnsPerHost)
+		require.Equal(t, 20, transport.MaxIdleConns)
+		require.Equal(t, 5, transport.MaxIdleConnsPerHost)
+		require.Equal(t, 30*time.Second, transport.IdleConnTimeout)
+		require.True(t, transport.DisableKeepAlives)
+		require.True(t, transport.ForceAttemptHTTP2)
+	})
+
+	t.Run("ca file", func(t *testing.T) {
+		srv := httptest.NewTLSServer(http.NotFoundHandler())
+		defer srv.Close()
+
+		caFile := filepath.Join(t.TempDir(), "ca.pem")
+		require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o600))
+
+		transport, err := newTransport(map[string]string{"ca_file": caFile})
+		require.NoError(t, err)
+		require.NotNil(t, transport.TLSClientConfig.RootCAs)
+
+		resp, err := (&http.Client{Transport: transport}).Get(srv.URL)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	})
+
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	require.NoError(t, os.WriteFile(empty, []byte("no certificates"), 0o600))
+
+	for name, params := range map[string]map[string]string{
+		"no_verify_ssl":           {"no_verify_ssl": "maybe"},
+		"max_conns_per_host":      {"max_conns_per_host": "-1"},
+		"max_idle_conns":          {"max_idle_conns": "many"},
+		"max_idle_conns_per_host": {"max_idle_conns_per_host": "1.5"},
+		"idle_timeout":            {"idle_timeout": "30"},
+		"disable_keep_alive":      {"disable_keep_alive": "yes"},
+		"http2":                   {"http2": "h2"},
+		"missing ca_file":         {"ca_file": filepath.Join(t.TempDir(), "missing.pem")},
+		"empty ca_file":           {"ca_file": empty},
+		"cert_file without key":   {"cert_file": empty},
+		"key_file without cert":   {"key_file": empty},
+		"invalid certificate":     {"cert_file": empty, "key_file": empty},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := newTransport(params)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestConnStats(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		name          string
+		params        map[string]string
+		newConns      float64
+		reusedConns   float64
+		requestsCount int
+	}{
+		{name: "keep alive", params: map[string]string{}, newConns: 1, reusedConns: 2, requestsCount: 3},
+		{name: "disabled keep alive", params: map[string]string{"disable_keep_alive": "true"}, newConns: 3, requestsCount: 3},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cli, samples := newTestClient(t, srv.URL, tc.params)
+			for range tc.requestsCount {
+				resp := cli.Delete("bucket", "key")
+				require.True(t, resp.Success, resp.Error)
+			}
+
+			var newConns, reusedConns float64
+			reported := collectSamples(samples)
+			for _, s := range reported[connNewTotal] {
+				newConns += s.Value
+			}
+			for _, s := range reported[connReusedTotal] {
+				reusedConns += s.Value
+			}
+			require.Equal(t, tc.newConns, newConns)
+			require.Equal(t, tc.reusedConns, reusedConns)
+		})
+	}
+}
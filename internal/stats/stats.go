@@ -18,6 +18,18 @@ func Report(vu modules.VU, metric *metrics.Metric, value float64) {
 	})
 }
 
+// ReportWithTags is the same as Report, but adds specified tags to the VU ones.
+func ReportWithTags(vu modules.VU, metric *metrics.Metric, value float64, tags map[string]string) {
+	metrics.PushIfNotDone(vu.Context(), vu.State().Samples, metrics.Sample{
+		TimeSeries: metrics.TimeSeries{
+			Metric: metric,
+			Tags:   vu.State().Tags.GetCurrentValues().Tags.WithTagsFromMap(tags),
+		},
+		Time:  time.Now(),
+		Value: value,
+	})
+}
+
 func ReportDataReceived(vu modules.VU, value float64) {
 	vu.State().BuiltinMetrics.DataReceived.Sink.Add(
 		metrics.Sample{
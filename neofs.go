@@ -4,6 +4,8 @@ import (
 	// In fact, xk6_neofs is a main module, but with different name. Leave a comment here to solve linter warning.
 	_ "github.com/nspcc-dev/xk6-neofs/internal/datagen"
 	// In fact, xk6_neofs is a main module, but with different name. Leave a comment here to solve linter warning.
+	_ "github.com/nspcc-dev/xk6-neofs/internal/http"
+	// In fact, xk6_neofs is a main module, but with different name. Leave a comment here to solve linter warning.
 	_ "github.com/nspcc-dev/xk6-neofs/internal/native"
 	// In fact, xk6_neofs is a main module, but with different name. Leave a comment here to solve linter warning.
 	_ "github.com/nspcc-dev/xk6-neofs/internal/registry"
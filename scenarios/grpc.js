@@ -20,7 +20,10 @@ const grpc_endpoint = grpc_endpoints[Math.floor(Math.random() * grpc_endpoints.l
 const grpc_client = native.connect(grpc_endpoint, '', __ENV.DIAL_TIMEOUT ? parseInt(__ENV.DIAL_TIMEOUT) : 5, __ENV.STREAM_TIMEOUT ? parseInt(__ENV.STREAM_TIMEOUT) : 15);
 
 const registry_enabled = !!__ENV.REGISTRY_FILE;
-const obj_registry = registry_enabled ? registry.open(__ENV.REGISTRY_FILE) : undefined;
+const obj_registry = registry_enabled ? registry.open(__ENV.REGISTRY_FILE, {
+    batch_size: __ENV.REGISTRY_BATCH_SIZE || "0",
+    stats_interval: __ENV.REGISTRY_STATS_INTERVAL || "0",
+}) : undefined;
 
 const duration = __ENV.DURATION;
 
@@ -38,8 +41,24 @@ if (registry_enabled && delete_age) {
     );
 }
 
+// Readers take objects from the registry instead of pregenerated ones if
+// selection mode is specified
+let obj_to_read_selector = undefined;
+if (registry_enabled && __ENV.READ_SELECTOR) {
+    obj_to_read_selector = registry.getSelector(
+        __ENV.REGISTRY_FILE,
+        "obj_to_read",
+        __ENV.SELECTION_SIZE ? parseInt(__ENV.SELECTION_SIZE) : 0,
+        {},
+        { mode: __ENV.READ_SELECTOR }
+    );
+}
 
-const generator = datagen.generator(1024 * parseInt(__ENV.WRITE_OBJ_SIZE));
+
+// Seeded generator makes payloads reproducible, so registry stores payload seeds instead of hashes
+const payload_seed = __ENV.PAYLOAD_SEED;
+const generator_params = payload_seed ? { seed: payload_seed } : {};
+const generator = datagen.generator(1024 * parseInt(__ENV.WRITE_OBJ_SIZE), generator_params);
 
 const scenarios = {};
 
@@ -113,7 +132,7 @@ export function obj_write() {
     };
     const container = container_list[Math.floor(Math.random() * container_list.length)];
 
-    const { payload, hash } = generator.genPayload(registry_enabled);
+    const { payload, hash, seed } = generator.genPayload(registry_enabled && !payload_seed);
     const resp = grpc_client.put(container, headers, payload);
     if (!resp.success) {
         console.log({cid: container, error: resp.error});
@@ -121,7 +140,11 @@ export function obj_write() {
     }
 
     if (obj_registry) {
-        obj_registry.addObject(container, resp.object_id, "", "", hash);
+        if (seed) {
+            obj_registry.addSeededObject(container, resp.object_id, "", "", seed, payload.byteLength, generator_params);
+        } else {
+            obj_registry.addObject(container, resp.object_id, "", "", hash, payload.byteLength);
+        }
     }
 }
 
@@ -130,7 +153,14 @@ export function obj_read() {
         sleep(__ENV.SLEEP_READ);
     }
 
-    const obj = obj_list[Math.floor(Math.random() * obj_list.length)];
+    let obj = obj_list[Math.floor(Math.random() * obj_list.length)];
+    if (obj_to_read_selector) {
+        const registry_obj = obj_to_read_selector.nextObject();
+        if (!registry_obj || !registry_obj.c_id) {
+            return;
+        }
+        obj = { container: registry_obj.c_id, object: registry_obj.o_id };
+    }
     const resp = grpc_client.get(obj.container, obj.object)
     if (!resp.success) {
         console.log({cid: obj.container, oid: obj.object, error: resp.error});
@@ -151,6 +181,7 @@ export function obj_delete() {
     if (!resp.success) {
         // Log errors except (2052 - object already deleted)
         console.log({cid: obj.c_id, oid: obj.o_id, error: resp.error});
+        obj_registry.addAttempt(obj.id, "delete", resp.error);
         return;
     }
 
@@ -1,6 +1,6 @@
 import datagen from 'k6/x/neofs/datagen';
 import registry from 'k6/x/neofs/registry';
-import http from 'k6/http';
+import http from 'k6/x/neofs/http';
 import { SharedArray } from 'k6/data';
 import { sleep } from 'k6';
 
@@ -17,13 +17,20 @@ const read_size = JSON.parse(open(__ENV.PREGEN_JSON)).obj_size;
 // Select random HTTP endpoint for current VU
 const http_endpoints = __ENV.HTTP_ENDPOINTS.split(',');
 const http_endpoint = http_endpoints[Math.floor(Math.random() * http_endpoints.length)];
+const http_client = http.connect(`http://${http_endpoint}`, {});
 
 const registry_enabled = !!__ENV.REGISTRY_FILE;
-const obj_registry = registry_enabled ? registry.open(__ENV.REGISTRY_FILE) : undefined;
+const obj_registry = registry_enabled ? registry.open(__ENV.REGISTRY_FILE, {
+    batch_size: __ENV.REGISTRY_BATCH_SIZE || "0",
+    stats_interval: __ENV.REGISTRY_STATS_INTERVAL || "0",
+}) : undefined;
 
 const duration = __ENV.DURATION;
 
-const generator = datagen.generator(1024 * parseInt(__ENV.WRITE_OBJ_SIZE));
+// Seeded generator makes payloads reproducible, so registry stores payload seeds instead of hashes
+const payload_seed = __ENV.PAYLOAD_SEED;
+const generator_params = payload_seed ? { seed: payload_seed } : {};
+const generator = datagen.generator(1024 * parseInt(__ENV.WRITE_OBJ_SIZE), generator_params);
 
 const scenarios = {};
 
@@ -78,20 +85,19 @@ export function obj_write() {
 
     const container = container_list[Math.floor(Math.random() * container_list.length)];
 
-    const { payload, hash } = generator.genPayload(registry_enabled);
-    const data = {
-        field: uuidv4(),
-        file: http.file(payload, "random.data"),
-    };
-
-    const resp = http.post(`http://${http_endpoint}/upload/${container}`, data);
-    if (resp.status != 200) {
-        console.log(`ERROR: ${resp.status} ${resp.error}`);
+    const { payload, hash, seed } = generator.genPayload(registry_enabled && !payload_seed);
+    const resp = http_client.upload(container, "random.data", payload, {});
+    if (!resp.success) {
+        console.log(`ERROR: ${resp.error}`);
         return;
     }
-    const object_id = JSON.parse(resp.body).object_id;
+    const object_id = resp.object_id;
     if (obj_registry) {
-        obj_registry.addObject(container, object_id, "", "", hash);
+        if (seed) {
+            obj_registry.addSeededObject(container, object_id, "", "", seed, payload.byteLength, generator_params);
+        } else {
+            obj_registry.addObject(container, object_id, "", "", hash, payload.byteLength);
+        }
     }
 }
 
@@ -101,15 +107,8 @@ export function obj_read() {
     }
 
     const obj = obj_list[Math.floor(Math.random() * obj_list.length)];
-    const resp = http.get(`http://${http_endpoint}/get/${obj.container}/${obj.object}`);
-    if (resp.status != 200) {
-        console.log(`ERROR reading ${obj.object}: ${resp.status}`);
+    const resp = http_client.download(obj.container, obj.object);
+    if (!resp.success) {
+        console.log(`ERROR reading ${obj.object}: ${resp.error}`);
     }
 }
-
-export function uuidv4() {
-    return 'xxxxxxxx-xxxx-4xxx-yxxx-xxxxxxxxxxxx'.replace(/[xy]/g, function(c) {
-        let r = Math.random() * 16 | 0, v = c === 'x' ? r : (r & 0x3 | 0x8);
-        return v.toString(16);
-    });
-}
@@ -22,7 +22,10 @@ console.log(`VU ID: ${__VU}, S3 endpoint in use: ${s3_endpoint}`);
 const s3_client = s3.connect(`http://${s3_endpoint}`);
 
 const registry_enabled = !!__ENV.REGISTRY_FILE;
-const obj_registry = registry_enabled ? registry.open(__ENV.REGISTRY_FILE) : undefined;
+const obj_registry = registry_enabled ? registry.open(__ENV.REGISTRY_FILE, {
+    batch_size: __ENV.REGISTRY_BATCH_SIZE || "0",
+    stats_interval: __ENV.REGISTRY_STATS_INTERVAL || "0",
+}) : undefined;
 
 const duration = __ENV.DURATION;
 
@@ -40,7 +43,23 @@ if (registry_enabled && delete_age) {
     );
 }
 
-const generator = datagen.generator(1024 * parseInt(__ENV.WRITE_OBJ_SIZE));
+// Readers take objects from the registry instead of pregenerated ones if
+// selection mode is specified
+let obj_to_read_selector = undefined;
+if (registry_enabled && __ENV.READ_SELECTOR) {
+    obj_to_read_selector = registry.getSelector(
+        __ENV.REGISTRY_FILE,
+        "obj_to_read",
+        __ENV.SELECTION_SIZE ? parseInt(__ENV.SELECTION_SIZE) : 0,
+        {},
+        { mode: __ENV.READ_SELECTOR }
+    );
+}
+
+// Seeded generator makes payloads reproducible, so registry stores payload seeds instead of hashes
+const payload_seed = __ENV.PAYLOAD_SEED;
+const generator_params = payload_seed ? { seed: payload_seed } : {};
+const generator = datagen.generator(1024 * parseInt(__ENV.WRITE_OBJ_SIZE), generator_params);
 
 const scenarios = {};
 
@@ -112,7 +131,7 @@ export function obj_write() {
     const key = __ENV.OBJ_NAME || uuidv4();
     const bucket = bucket_list[Math.floor(Math.random() * bucket_list.length)];
 
-    const { payload, hash } = generator.genPayload(registry_enabled);
+    const { payload, hash, seed } = generator.genPayload(registry_enabled && !payload_seed);
     const resp = s3_client.put(bucket, key, payload);
     if (!resp.success) {
         console.log(resp.error);
@@ -120,7 +139,11 @@ export function obj_write() {
     }
 
     if (obj_registry) {
-        obj_registry.addObject("", "", bucket, key, hash);
+        if (seed) {
+            obj_registry.addSeededObject("", "", bucket, key, seed, payload.byteLength, generator_params);
+        } else {
+            obj_registry.addObject("", "", bucket, key, hash, payload.byteLength);
+        }
     }
 }
 
@@ -129,7 +152,14 @@ export function obj_read() {
         sleep(__ENV.SLEEP_READ);
     }
 
-    const obj = obj_list[Math.floor(Math.random() * obj_list.length)];
+    let obj = obj_list[Math.floor(Math.random() * obj_list.length)];
+    if (obj_to_read_selector) {
+        const registry_obj = obj_to_read_selector.nextObject();
+        if (!registry_obj || !registry_obj.s3_bucket) {
+            return;
+        }
+        obj = { bucket: registry_obj.s3_bucket, object: registry_obj.s3_key };
+    }
 
     const resp = s3_client.get(obj.bucket, obj.object);
     if (!resp.success) {
@@ -150,6 +180,7 @@ export function obj_delete() {
     const resp = s3_client.delete(obj.s3_bucket, obj.s3_key);
     if (!resp.success) {
         console.log(`Error deleting object ${obj.id}: ${resp.error}`);
+        obj_registry.addAttempt(obj.id, "delete", resp.error);
         return;
     }
 
@@ -1,10 +1,12 @@
+import datagen from 'k6/x/neofs/datagen';
+import http from 'k6/x/neofs/http';
 import native from 'k6/x/neofs/native';
 import registry from 'k6/x/neofs/registry';
 import s3 from 'k6/x/neofs/s3';
 import { sleep } from 'k6';
 import { Counter } from 'k6/metrics';
 
-const obj_registry = registry.open(__ENV.REGISTRY_FILE);
+const obj_registry = registry.open(__ENV.REGISTRY_FILE, { stats_interval: __ENV.REGISTRY_STATS_INTERVAL || "0" });
 
 // Time limit (in seconds) for the run
 const time_limit = __ENV.TIME_LIMIT || "60";
@@ -36,6 +38,14 @@ if (__ENV.S3_ENDPOINTS) {
     s3_client = s3.connect(`http://${s3_endpoint}`);
 }
 
+// Connect to random HTTP endpoint
+let http_client = undefined;
+if (__ENV.HTTP_ENDPOINTS) {
+    const http_endpoints = __ENV.HTTP_ENDPOINTS.split(',');
+    const http_endpoint = http_endpoints[Math.floor(Math.random() * http_endpoints.length)];
+    http_client = http.connect(`http://${http_endpoint}`, {});
+}
+
 // We will attempt to verify every object in "created" status. The scenario will execute
 // as many iterations as there are objects. Each object will have 3 retries to be verified
 const obj_to_verify_selector = registry.getSelector(
@@ -70,13 +80,9 @@ export const options = {
 
 export function setup() {
     // Populate counters with initial values
+    const stats = obj_registry.stats();
     for (const [status, counter] of Object.entries(obj_counters)) {
-        const obj_selector = registry.getSelector(
-            __ENV.REGISTRY_FILE,
-            status,
-            __ENV.SELECTION_SIZE ? parseInt(__ENV.SELECTION_SIZE) : 0,
-            { status });
-        counter.add(obj_selector.count());
+        counter.add(stats.statuses[status] || 0);
     }
 }
 
@@ -97,10 +103,24 @@ export function obj_verify() {
 }
 
 function verify_object_with_retries(obj, attempts) {
+    // Objects generated by seeded generator have no stored hash, it is calculated from the seed
+    // and payload mode parameters the generator was created with
+    if (!obj.payload_hash && obj.payload_seed) {
+        obj.payload_hash = datagen.payloadHash(obj.payload_size, obj.payload_seed, obj.payload_params || {});
+    }
+
+    // Objects imported from preset have neither hash nor seed, there is nothing to compare payload with
+    if (!obj.payload_hash) {
+        console.log(`Object id=${obj.id} has no payload hash to be verified with`);
+        return "skipped";
+    }
+
     for (let i = 0; i < attempts; i++) {
         let result;
-        if (obj.c_id && obj.o_id) {
+        if (obj.c_id && obj.o_id && grpc_client) {
             result = grpc_client.verifyHash(obj.c_id, obj.o_id, obj.payload_hash);
+        } else if (obj.c_id && obj.o_id && http_client) {
+            result = http_client.verifyHash(obj.c_id, obj.o_id, obj.payload_hash);
         } else if (obj.s3_bucket && obj.s3_key) {
             result = s3_client.verifyHash(obj.s3_bucket, obj.s3_key, obj.payload_hash);
         } else {
@@ -108,6 +128,7 @@ function verify_object_with_retries(obj, attempts) {
             return "skipped";
         }
 
+        obj_registry.addAttempt(obj.id, "verify", result.error);
         if (result.success) {
             return "verified";
         } else if (result.error == "hash mismatch") {